@@ -21,11 +21,21 @@ func Commands() map[string]cli.CommandFactory {
 	}
 
 	return map[string]cli.CommandFactory{
+		"reindex": func() (cli.Command, error) {
+			return &ReindexCommand{
+				UI: ui,
+			}, nil
+		},
 		"server": func() (cli.Command, error) {
 			return &ServerCommand{
 				UI: ui,
 			}, nil
 		},
+		"status": func() (cli.Command, error) {
+			return &StatusCommand{
+				UI: ui,
+			}, nil
+		},
 	}
 }
 
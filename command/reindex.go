@@ -0,0 +1,92 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/mitchellh/cli"
+	"github.com/umbracle/eth-indexer/indexer"
+	"github.com/umbracle/eth-indexer/indexer/rpcpool"
+)
+
+// ReindexCommand is the command to reindex the providers with the archived logs
+type ReindexCommand struct {
+	UI cli.Ui
+}
+
+// Help implements the cli.Command interface
+func (c *ReindexCommand) Help() string {
+	return `Usage: eth-indexer reindex [options]
+
+  Drops the tables of the providers and replays the handlers with the
+  logs stored in the archive. The endpoint is only used by the handlers
+  that query the chain.
+
+  Only the logs are archived. The providers with call or block trackers
+  cannot be reindexed, their calls and block ticks are not in the archive
+  and they have to be indexed again from the chain.`
+}
+
+// Synopsis implements the cli.Command interface
+func (c *ReindexCommand) Synopsis() string {
+	return "Reindex the providers with the archived logs"
+}
+
+// Run implements the cli.Command interface
+func (c *ReindexCommand) Run(args []string) int {
+	flags := flag.NewFlagSet("reindex", flag.ContinueOnError)
+	flags.Usage = func() {}
+
+	var endpoint string
+	var database string
+	var provider string
+	var failurePolicy string
+
+	flags.StringVar(&endpoint, "endpoint", "", "")
+	flags.StringVar(&database, "database", "postgres://postgres@localhost:5432/postgres?sslmode=disable", "")
+	flags.StringVar(&provider, "provider", "pancake", "")
+	flags.StringVar(&failurePolicy, "failure-policy", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		c.UI.Error(fmt.Sprintf("failed to parse args: %v", err))
+		return 1
+	}
+
+	// the endpoint flag is a comma separated list of endpoints
+	endpoints, err := rpcpool.ParseEndpoints(endpoint)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("failed to parse the endpoints: %v", err))
+		return 1
+	}
+
+	policies, err := parseFailurePolicies(failurePolicy)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("failed to parse the failure policies: %v", err))
+		return 1
+	}
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:  "indexer",
+		Level: hclog.LevelFromString("debug"),
+	})
+
+	config := &indexer.Config{
+		Endpoints:     endpoints,
+		Database:      database,
+		Providers:     strings.Split(provider, ","),
+		Reindex:       true,
+		FailurePolicy: policies,
+	}
+	if _, err := indexer.NewServer(config, logger); err != nil {
+		if errors.Is(err, indexer.ErrReindexUnsupported) {
+			c.UI.Error(fmt.Sprintf("Reindex is not supported: %v. Only the providers with log trackers can be reindexed, see 'eth-indexer reindex -help'", err))
+			return 1
+		}
+		c.UI.Error(fmt.Sprintf("Failed to reindex: %v", err))
+		return 1
+	}
+	return 0
+}
@@ -6,6 +6,7 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -13,6 +14,8 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/mitchellh/cli"
 	"github.com/umbracle/eth-indexer/indexer"
+	"github.com/umbracle/eth-indexer/indexer/rpcpool"
+	"github.com/umbracle/eth-indexer/sdk"
 )
 
 // ServerCommand is the ServerCommand to run the agent
@@ -44,44 +47,117 @@ func (c *ServerCommand) Run(args []string) int {
 	var database string
 	var batchSize uint64
 	var provider string
+	var failurePolicy string
+	var httpAddr string
+	var dryRun bool
+	var maxReorgDepth uint64
 
 	flags.StringVar(&endpoint, "endpoint", "", "")
 	flags.StringVar(&database, "database", "postgres://postgres@localhost:5432/postgres?sslmode=disable", "")
 	flags.Uint64Var(&batchSize, "batch-size", 5000, "")
 	flags.StringVar(&provider, "provider", "pancake", "")
+	flags.StringVar(&failurePolicy, "failure-policy", "", "")
+	flags.StringVar(&httpAddr, "http-addr", "", "")
+	flags.BoolVar(&dryRun, "dry-run", false, "")
+	flags.Uint64Var(&maxReorgDepth, "max-reorg-depth", 0, "")
 
 	if err := flags.Parse(args); err != nil {
 		c.UI.Error(fmt.Sprintf("failed to parse args: %v", err))
 		return 1
 	}
 
+	// the endpoint flag is a comma separated list of endpoints
+	endpoints, err := rpcpool.ParseEndpoints(endpoint)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("failed to parse the endpoints: %v", err))
+		return 1
+	}
+
+	policies, err := parseFailurePolicies(failurePolicy)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("failed to parse the failure policies: %v", err))
+		return 1
+	}
+
 	logger := hclog.New(&hclog.LoggerOptions{
 		Name:  "indexer",
 		Level: hclog.LevelFromString("debug"),
 	})
 
 	config := &indexer.Config{
-		GRPCAddr:        &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 6001},
-		JSONRPCEndpoint: endpoint,
-		Database:        database,
-		BatchSize:       batchSize,
-		Provider:        provider,
+		GRPCAddr:      &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 6001},
+		Endpoints:     endpoints,
+		Database:      database,
+		BatchSize:     batchSize,
+		Providers:     strings.Split(provider, ","),
+		HTTPAddr:      httpAddr,
+		FailurePolicy: policies,
+		DryRun:        dryRun,
+		MaxReorgDepth: maxReorgDepth,
 	}
 	srv, err := indexer.NewServer(config, logger)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Failed to start the server: %v", err))
 		return 1
 	}
-	return c.handleSignals(srv.Stop)
+	if dryRun {
+		c.UI.Output(formatMigrations(srv.Migrations()))
+		return 0
+	}
+	return c.handleSignals(srv.Stop, srv.HaltCh())
+}
+
+// formatMigrations prints the planned migrations as an SQL script
+func formatMigrations(migrations []*indexer.TableMigration) string {
+	lines := []string{}
+	for _, m := range migrations {
+		lines = append(lines, fmt.Sprintf("-- table %s (version %d)", m.Table, m.Version))
+		for _, warning := range m.Warnings {
+			lines = append(lines, "-- warning: "+warning)
+		}
+		if len(m.DDL) == 0 && len(m.Indexes) == 0 {
+			lines = append(lines, "-- no changes")
+		}
+		for _, ddl := range append(m.DDL, m.Indexes...) {
+			lines = append(lines, ddl+";")
+		}
+	}
+	return strings.Join(lines, "\n")
 }
 
-func (c *ServerCommand) handleSignals(closeFn func()) int {
+// parseFailurePolicies parses a comma separated list of provider=policy
+func parseFailurePolicies(str string) (map[string]sdk.FailurePolicy, error) {
+	res := map[string]sdk.FailurePolicy{}
+	for _, item := range strings.Split(str, ",") {
+		if item == "" {
+			continue
+		}
+		spl := strings.SplitN(item, "=", 2)
+		if len(spl) != 2 {
+			return nil, fmt.Errorf("expected provider=policy but found '%s'", item)
+		}
+		policy, err := sdk.ParseFailurePolicy(spl[1])
+		if err != nil {
+			return nil, err
+		}
+		res[spl[0]] = policy
+	}
+	return res, nil
+}
+
+func (c *ServerCommand) handleSignals(closeFn func(), haltCh <-chan error) int {
 	signalCh := make(chan os.Signal, 4)
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
 	var sig os.Signal
 	select {
 	case sig = <-signalCh:
+	case err := <-haltCh:
+		c.UI.Error(fmt.Sprintf("Indexer halted: %v", err))
+		if closeFn != nil {
+			closeFn()
+		}
+		return 1
 	}
 
 	c.UI.Output(fmt.Sprintf("Caught signal: %v", sig))
@@ -0,0 +1,111 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/umbracle/eth-indexer/indexer"
+)
+
+// StatusCommand is the command to show the sync progress of a running indexer
+type StatusCommand struct {
+	UI cli.Ui
+}
+
+// Help implements the cli.Command interface
+func (c *StatusCommand) Help() string {
+	return `Usage: eth-indexer status [options]
+
+  Shows the sync progress of the tracks and the failures of the providers
+  of an indexer started with the http-addr flag.
+
+  -address  Address of the http api of the indexer (default 127.0.0.1:6060)`
+}
+
+// Synopsis implements the cli.Command interface
+func (c *StatusCommand) Synopsis() string {
+	return "Show the sync progress of the indexer"
+}
+
+// Run implements the cli.Command interface
+func (c *StatusCommand) Run(args []string) int {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	flags.Usage = func() {}
+
+	var addr string
+	flags.StringVar(&addr, "address", "127.0.0.1:6060", "")
+
+	if err := flags.Parse(args); err != nil {
+		c.UI.Error(fmt.Sprintf("failed to parse args: %v", err))
+		return 1
+	}
+
+	status, err := getStatus(addr)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("failed to get the status: %v", err))
+		return 1
+	}
+	c.UI.Output(formatStatus(status))
+	return 0
+}
+
+func getStatus(addr string) (*indexer.Status, error) {
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(addr + "/v1/status")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	var status indexer.Status
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func formatStatus(status *indexer.Status) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Head: %d\n", status.Head)
+
+	for _, p := range status.Providers {
+		fmt.Fprintf(&buf, "\nProvider: %s (policy %s, failures %d)\n", p.Name, p.Policy, p.Failures)
+		if p.Err != "" {
+			fmt.Fprintf(&buf, "Stopped: %s\n", p.Err)
+		}
+		if p.LastFailure != "" {
+			fmt.Fprintf(&buf, "Last failure: %s\n", p.LastFailure)
+		}
+		if len(p.Quarantined) != 0 {
+			fmt.Fprintf(&buf, "Quarantined: %d contracts\n", len(p.Quarantined))
+		}
+
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TRACK\tBLOCK\tHEAD\tPROGRESS\tEVENTS\tDIFFS\tBLOCKS/S\tEVENTS/S\tETA")
+		for _, t := range p.Tracks {
+			eta := "-"
+			if t.Synced {
+				eta = "synced"
+			} else if t.ETA != 0 {
+				eta = t.ETA.String()
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%.2f%%\t%d\t%d\t%.2f\t%.2f\t%s\n",
+				t.Name, t.Block, t.Head, t.Percent, t.Events, t.Diffs, t.BlocksPerSec, t.EventsPerSec, eta)
+		}
+		w.Flush()
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
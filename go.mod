@@ -3,7 +3,6 @@ module github.com/umbracle/eth-indexer
 go 1.15
 
 require (
-	github.com/boltdb/bolt v1.3.1
 	github.com/golang/protobuf v1.4.2
 	github.com/google/gops v0.3.18
 	github.com/google/uuid v1.2.0
@@ -21,26 +21,51 @@ type tuple struct {
 }
 
 func (s *Server) Register(sch *sdk.Schema) {
+	schema, err := s.buildSchema(sch)
+	if err != nil {
+		log.Fatalf("failed to create new schema, error: %v", err)
+	}
+
+	// Query
+
+	query := `
+	query {
+		swap_events (first: 10, where: {pair: {eq: "foo"}}) {
+			id
+		}
+	}
+	`
+
+	params := graphql.Params{Schema: schema, RequestString: query}
+	r := graphql.Do(params)
+	if len(r.Errors) > 0 {
+		fmt.Println(r.Errors[0])
+	}
+	rJSON, _ := json.Marshal(r)
+	fmt.Printf("%s \n", rJSON) // {"data":{"hello":"world"}}
+}
+
+// buildSchema builds the GraphQL schema of the tables
+func (s *Server) buildSchema(sch *sdk.Schema) (graphql.Schema, error) {
+	// the objects are built lazily since the references
+	// between the tables might be cyclic
+	objsByName := map[string]*tuple{}
+
 	var objs []*tuple
 	for _, table := range sch.Tables {
-		graphqlFields := graphql.Fields{}
-		for _, f := range table.Fields {
-			graphqlFields[f.Name] = &graphql.Field{
-				Type: graphql.NewNonNull(graphql.String),
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-					obj := p.Source.(*sdk.Obj)
-					return obj.Data[p.Info.FieldName], nil
-				},
-			}
-		}
+		table := table
 		obj := graphql.NewObject(graphql.ObjectConfig{
-			Name:   strings.Title(table.Name),
-			Fields: graphqlFields,
+			Name: strings.Title(table.Name),
+			Fields: graphql.FieldsThunk(func() graphql.Fields {
+				return s.buildFields(table, sch, objsByName)
+			}),
 		})
-		objs = append(objs, &tuple{
+		tt := &tuple{
 			obj:   obj,
 			table: table,
-		})
+		}
+		objs = append(objs, tt)
+		objsByName[table.Name] = tt
 	}
 
 	queryFields := graphql.Fields{}
@@ -119,26 +144,96 @@ func (s *Server) Register(sch *sdk.Schema) {
 	schemaConfig := graphql.SchemaConfig{
 		Query: queryType,
 	}
-	schema, err := graphql.NewSchema(schemaConfig)
-	if err != nil {
-		log.Fatalf("failed to create new schema, error: %v", err)
+	return graphql.NewSchema(schemaConfig)
+}
+
+// buildFields returns the fields of the GraphQL type of the table. The references
+// resolve the referenced object and the referenced tables include the list of the
+// objects that reference them.
+func (s *Server) buildFields(table *sdk.Table, sch *sdk.Schema, objs map[string]*tuple) graphql.Fields {
+	graphqlFields := graphql.Fields{}
+	for _, f := range table.Fields {
+		field := f
+
+		if ref := field.References; ref != nil {
+			graphqlFields[field.Name] = &graphql.Field{
+				Type: objs[ref.Table].obj,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					obj := p.Source.(*sdk.Obj)
+					val, ok := lookupData(obj, field.Name)
+					if !ok {
+						return nil, nil
+					}
+					refObj, err := s.resolver.GetObj2(ref.Table, map[string]string{ref.Field: val})
+					if err != nil || refObj == nil {
+						return nil, err
+					}
+					return refObj, nil
+				},
+			}
+			continue
+		}
+
+		// only the ids are always set
+		typ := fieldType(field)
+		if field.ID {
+			typ = graphql.NewNonNull(typ)
+		}
+		graphqlFields[field.Name] = &graphql.Field{
+			Type: typ,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				obj := p.Source.(*sdk.Obj)
+				val, ok := lookupData(obj, p.Info.FieldName)
+				if !ok {
+					return nil, nil
+				}
+				// the scalars serialize the decoded values
+				return field.Decode(val)
+			},
+		}
 	}
 
-	// Query
+	// the reverse lists of the tables that reference this one
+	for _, other := range sch.Tables {
+		for _, f := range other.Fields {
+			if f.References == nil || f.References.Table != table.Name {
+				continue
+			}
+			field, otherName := f, other.Name
 
-	query := `
-	query {
-		swap_events (first: 10, where: {pair: {eq: "foo"}}) {
-			id
+			name := field.References.Reverse
+			if name == "" {
+				name = otherName + "s_" + field.Name
+			}
+			graphqlFields[name] = &graphql.Field{
+				Type: graphql.NewList(objs[otherName].obj),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					obj := p.Source.(*sdk.Obj)
+					val, _ := lookupData(obj, field.References.Field)
+					query := &sdk.Query{
+						Table: otherName,
+						Where: []sdk.QueryWhere{
+							{
+								Key:   field.Name,
+								Val:   val,
+								Where: sdk.WhereCondEqual,
+							},
+						},
+					}
+					return s.resolver.GetObjs2(query)
+				},
+			}
 		}
 	}
-	`
+	return graphqlFields
+}
 
-	params := graphql.Params{Schema: schema, RequestString: query}
-	r := graphql.Do(params)
-	if len(r.Errors) > 0 {
-		fmt.Println(r.Errors[0])
+// lookupData returns the value of the field in the object. The names of the
+// columns are lowercase since Postgresql folds the identifiers.
+func lookupData(obj *sdk.Obj, name string) (string, bool) {
+	val, ok := obj.Data[name]
+	if !ok {
+		val, ok = obj.Data[strings.ToLower(name)]
 	}
-	rJSON, _ := json.Marshal(r)
-	fmt.Printf("%s \n", rJSON) // {"data":{"hello":"world"}}
+	return val, ok
 }
@@ -0,0 +1,147 @@
+package graphql
+
+import (
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/umbracle/eth-indexer/sdk"
+	"github.com/umbracle/go-web3"
+)
+
+// BigIntType is an integer of arbitrary size serialized as a decimal string
+// since the GraphQL Int is limited to 32 bits
+var BigIntType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "BigInt",
+	Description: "The `BigInt` scalar type represents an integer of arbitrary size as a decimal string.",
+
+	Serialize: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case *big.Int:
+			return value.String()
+		case uint64:
+			return new(big.Int).SetUint64(value).String()
+		case int64:
+			return big.NewInt(value).String()
+		case string:
+			return parseBigInt(value)
+		default:
+			return nil
+		}
+	},
+
+	ParseValue: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case string:
+			return parseBigInt(value)
+		case int:
+			return big.NewInt(int64(value)).String()
+		default:
+			return nil
+		}
+	},
+
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch valueAST := valueAST.(type) {
+		case *ast.StringValue:
+			return parseBigInt(valueAST.Value)
+		case *ast.IntValue:
+			return parseBigInt(valueAST.Value)
+		default:
+			return nil
+		}
+	},
+})
+
+func parseBigInt(str string) interface{} {
+	num, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return nil
+	}
+	return num.String()
+}
+
+// BytesType is a byte array serialized as an hex string with the 0x prefix
+var BytesType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Bytes",
+	Description: "The `Bytes` scalar type represents a byte array as an hex string with the 0x prefix.",
+
+	Serialize: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case []byte:
+			return "0x" + hex.EncodeToString(value)
+		case web3.Hash:
+			return value.String()
+		default:
+			return nil
+		}
+	},
+
+	ParseValue: func(value interface{}) interface{} {
+		if value, ok := value.(string); ok {
+			return value
+		}
+		return nil
+	},
+
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if valueAST, ok := valueAST.(*ast.StringValue); ok {
+			return valueAST.Value
+		}
+		return nil
+	},
+})
+
+// TimestampType is a point in time serialized in the RFC3339 format
+var TimestampType = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Timestamp",
+	Description: "The `Timestamp` scalar type represents a point in time in the RFC3339 format.",
+
+	Serialize: func(value interface{}) interface{} {
+		if value, ok := value.(time.Time); ok {
+			return value.UTC().Format(time.RFC3339)
+		}
+		return nil
+	},
+
+	ParseValue: func(value interface{}) interface{} {
+		if value, ok := value.(string); ok {
+			return parseTimestamp(value)
+		}
+		return nil
+	},
+
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if valueAST, ok := valueAST.(*ast.StringValue); ok {
+			return parseTimestamp(valueAST.Value)
+		}
+		return nil
+	},
+})
+
+func parseTimestamp(str string) interface{} {
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return nil
+	}
+	return t
+}
+
+// fieldType returns the GraphQL type of the field
+func fieldType(f *sdk.Field) graphql.Output {
+	switch f.Type {
+	case sdk.TypeBool:
+		return graphql.Boolean
+	case sdk.TypeUint, sdk.TypeInt, sdk.TypeBigInt:
+		return BigIntType
+	case sdk.TypeBytes, sdk.TypeHash:
+		return BytesType
+	case sdk.TypeTimestamp:
+		return TimestampType
+	default:
+		// address, string and decimal
+		return graphql.String
+	}
+}
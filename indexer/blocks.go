@@ -0,0 +1,45 @@
+package indexer
+
+import (
+	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/eth-indexer/sdk"
+)
+
+// blockTrack builds the track that runs the block trackers of the provider
+func blockTrack(provider string, p *sdk.Provider) *proto.Track {
+	var startBlock uint64
+	for indx, t := range p.BlockTrackers {
+		if indx == 0 || t.StartBlock < startBlock {
+			startBlock = t.StartBlock
+		}
+	}
+	track := &proto.Track{
+		Name:     provider + ".blocks",
+		Provider: provider,
+		Blocks:   true,
+	}
+	// the polled tracks start after the start block
+	if startBlock != 0 {
+		track.StartBlock = startBlock - 1
+	}
+	return track
+}
+
+// startBlockTrack starts to run the block trackers of the provider
+func (t *trackerSrv) startBlockTrack(track *proto.Track, p *providerSrv) error {
+	t.startPolledTrack(track, p, func(from, to uint64) ([]*sdk.Action, error) {
+		actions := []*sdk.Action{}
+		for num := from; num <= to; num++ {
+			if !p.provider.IsBlockTick(num) {
+				continue
+			}
+			// the header of the block is resolved while processing the action
+			actions = append(actions, &sdk.Action{
+				BlockNum:  num,
+				BlockTick: true,
+			})
+		}
+		return actions, nil
+	})
+	return nil
+}
@@ -0,0 +1,332 @@
+package indexer
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+
+	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/eth-indexer/sdk"
+	"github.com/umbracle/go-web3"
+	"github.com/umbracle/go-web3/jsonrpc/codec"
+)
+
+// callTrackFromSource builds the track that indexes the calls
+// to the contracts of a source of the provider filter
+func callTrackFromSource(provider string, source *sdk.Source) *proto.Track {
+	addrs := append([]web3.Address{}, source.Address...)
+	addrs = append(addrs, source.To...)
+	if len(addrs) == 0 {
+		// the source does not filter by contract
+		return nil
+	}
+
+	track := &proto.Track{
+		Name:       provider + "." + source.Name + ".calls",
+		Provider:   provider,
+		ToAddr:     joinAddrs(addrs),
+		StartBlock: source.StartBlock,
+		Calls:      true,
+	}
+	return track
+}
+
+// startCallTrack starts to index the calls of the track
+func (t *trackerSrv) startCallTrack(track *proto.Track, p *providerSrv) error {
+	addrs, err := parseAddrs(track.ToAddr)
+	if err != nil {
+		return err
+	}
+	t.startPolledTrack(track, p, func(from, to uint64) ([]*sdk.Action, error) {
+		return t.getCalls(addrs, from, to)
+	})
+	return nil
+}
+
+// getCalls returns the calls to the addresses between the blocks 'from' and
+// 'to' grouped by block. It uses the trace_filter endpoint if the node supports
+// it, otherwise, it scans the transactions of the blocks.
+func (t *trackerSrv) getCalls(addrs []web3.Address, from, to uint64) ([]*sdk.Action, error) {
+	if atomic.LoadInt32(&t.noTraceFilter) == 0 {
+		actions, err := t.traceFilter(addrs, from, to)
+		if err == nil {
+			return actions, nil
+		}
+		if !isMethodNotFound(err) {
+			return nil, err
+		}
+		t.logger.Info("trace_filter not supported, scanning the blocks for calls")
+		atomic.StoreInt32(&t.noTraceFilter, 1)
+	}
+
+	actions := []*sdk.Action{}
+	for num := from; num <= to; num++ {
+		act, err := t.scanBlockCalls(addrs, num)
+		if err != nil {
+			return nil, err
+		}
+		if len(act.Calls) != 0 {
+			actions = append(actions, act)
+		}
+	}
+	return actions, nil
+}
+
+type traceFilterReq struct {
+	FromBlock string   `json:"fromBlock"`
+	ToBlock   string   `json:"toBlock"`
+	ToAddress []string `json:"toAddress"`
+}
+
+type trace struct {
+	Action struct {
+		CallType string `json:"callType"`
+		From     string `json:"from"`
+		To       string `json:"to"`
+		Input    string `json:"input"`
+		Value    string `json:"value"`
+	} `json:"action"`
+	Result *struct {
+		Output string `json:"output"`
+	} `json:"result"`
+	Error               string   `json:"error"`
+	Type                string   `json:"type"`
+	BlockHash           string   `json:"blockHash"`
+	BlockNumber         uint64   `json:"blockNumber"`
+	TransactionHash     string   `json:"transactionHash"`
+	TransactionPosition uint64   `json:"transactionPosition"`
+	TraceAddress        []uint64 `json:"traceAddress"`
+}
+
+func (t *trackerSrv) traceFilter(addrs []web3.Address, from, to uint64) ([]*sdk.Action, error) {
+	req := &traceFilterReq{
+		FromBlock: fmt.Sprintf("0x%x", from),
+		ToBlock:   fmt.Sprintf("0x%x", to),
+	}
+	for _, addr := range addrs {
+		req.ToAddress = append(req.ToAddress, addr.String())
+	}
+
+	var traces []*trace
+	if err := t.provider.Call("trace_filter", &traces, req); err != nil {
+		return nil, err
+	}
+	return decodeTraces(traces)
+}
+
+// decodeTraces returns the calls of the traces grouped by block. As with
+// debug_traceTransaction, the static and delegate calls are not included
+// since they do not run in the context of the contract.
+func decodeTraces(traces []*trace) ([]*sdk.Action, error) {
+	actions := []*sdk.Action{}
+	var act *sdk.Action
+	for _, tr := range traces {
+		if tr.Type != "call" || tr.Action.CallType != "call" || tr.Error != "" {
+			// only successful calls
+			continue
+		}
+		if act == nil || act.BlockNum != tr.BlockNumber {
+			act = &sdk.Action{
+				BlockNum: tr.BlockNumber,
+			}
+			if err := act.BlockHash.UnmarshalText([]byte(tr.BlockHash)); err != nil {
+				return nil, err
+			}
+			actions = append(actions, act)
+		}
+
+		call := &sdk.Call{
+			TxIndex:  tr.TransactionPosition,
+			Internal: len(tr.TraceAddress) != 0,
+		}
+		if err := call.TxHash.UnmarshalText([]byte(tr.TransactionHash)); err != nil {
+			return nil, err
+		}
+		if err := call.From.UnmarshalText([]byte(tr.Action.From)); err != nil {
+			return nil, err
+		}
+		if err := call.To.UnmarshalText([]byte(tr.Action.To)); err != nil {
+			return nil, err
+		}
+		var err error
+		if call.Value, err = parseHexBig(tr.Action.Value); err != nil {
+			return nil, err
+		}
+		if call.Input, err = parseHexBytes(tr.Action.Input); err != nil {
+			return nil, err
+		}
+		if tr.Result != nil {
+			if call.Output, err = parseHexBytes(tr.Result.Output); err != nil {
+				return nil, err
+			}
+		}
+		act.Calls = append(act.Calls, call)
+	}
+	return actions, nil
+}
+
+type callFrame struct {
+	Type   string       `json:"type"`
+	From   string       `json:"from"`
+	To     string       `json:"to"`
+	Value  string       `json:"value"`
+	Input  string       `json:"input"`
+	Output string       `json:"output"`
+	Error  string       `json:"error"`
+	Calls  []*callFrame `json:"calls"`
+}
+
+// scanBlockCalls returns the calls of the transactions to the addresses in the block.
+// If the node supports debug_traceTransaction the outputs and the internal calls of
+// those transactions are included too.
+func (t *trackerSrv) scanBlockCalls(addrs []web3.Address, num uint64) (*sdk.Action, error) {
+	block, err := t.provider.Eth().GetBlockByNumber(web3.BlockNumber(num), true)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", num)
+	}
+
+	isTarget := func(addr web3.Address) bool {
+		for _, a := range addrs {
+			if a == addr {
+				return true
+			}
+		}
+		return false
+	}
+
+	act := &sdk.Action{
+		BlockNum:  block.Number,
+		BlockHash: block.Hash,
+	}
+	for _, txn := range block.Transactions {
+		if txn.To == nil || !isTarget(*txn.To) {
+			continue
+		}
+
+		if atomic.LoadInt32(&t.noDebugTrace) == 0 {
+			var frame *callFrame
+			err := t.provider.Call("debug_traceTransaction", &frame, txn.Hash, map[string]string{"tracer": "callTracer"})
+			if err != nil {
+				if !isMethodNotFound(err) {
+					return nil, err
+				}
+				t.logger.Info("debug_traceTransaction not supported, the calls do not include outputs")
+				atomic.StoreInt32(&t.noDebugTrace, 1)
+			} else if frame == nil {
+				// the node did not trace this transaction, use the transaction itself
+				t.logger.Warn("debug_traceTransaction returned an empty trace, the call does not include the output", "tx", txn.Hash)
+			} else {
+				calls, err := flattenCallFrame(frame, txn, isTarget, false)
+				if err != nil {
+					return nil, err
+				}
+				act.Calls = append(act.Calls, calls...)
+				continue
+			}
+		}
+
+		// without traces, the status of the receipt tells whether the call reverted
+		var receipt struct {
+			Status string `json:"status"`
+		}
+		if err := t.provider.Call("eth_getTransactionReceipt", &receipt, txn.Hash); err != nil {
+			return nil, err
+		}
+		if receipt.Status == "0x0" {
+			continue
+		}
+		act.Calls = append(act.Calls, &sdk.Call{
+			TxHash:  txn.Hash,
+			TxIndex: txn.TxnIndex,
+			From:    txn.From,
+			To:      *txn.To,
+			Value:   txn.Value,
+			Input:   txn.Input,
+		})
+	}
+	return act, nil
+}
+
+func flattenCallFrame(frame *callFrame, txn *web3.Transaction, isTarget func(web3.Address) bool, internal bool) ([]*sdk.Call, error) {
+	if frame.Error != "" {
+		// the call and its subcalls reverted
+		return nil, nil
+	}
+
+	res := []*sdk.Call{}
+	if frame.Type == "CALL" || frame.Type == "" {
+		var to web3.Address
+		if err := to.UnmarshalText([]byte(frame.To)); err != nil {
+			return nil, err
+		}
+		if isTarget(to) {
+			call := &sdk.Call{
+				TxHash:   txn.Hash,
+				TxIndex:  txn.TxnIndex,
+				To:       to,
+				Internal: internal,
+			}
+			if err := call.From.UnmarshalText([]byte(frame.From)); err != nil {
+				return nil, err
+			}
+			var err error
+			if call.Value, err = parseHexBig(frame.Value); err != nil {
+				return nil, err
+			}
+			if call.Input, err = parseHexBytes(frame.Input); err != nil {
+				return nil, err
+			}
+			if call.Output, err = parseHexBytes(frame.Output); err != nil {
+				return nil, err
+			}
+			res = append(res, call)
+		}
+	}
+	for _, sub := range frame.Calls {
+		calls, err := flattenCallFrame(sub, txn, isTarget, true)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, calls...)
+	}
+	return res, nil
+}
+
+// isMethodNotFound returns true if the node does not support the endpoint
+func isMethodNotFound(err error) bool {
+	var obj *codec.ErrorObject
+	if errors.As(err, &obj) {
+		if obj.Code == -32601 {
+			return true
+		}
+		msg := strings.ToLower(obj.Message)
+		return strings.Contains(msg, "not found") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "not supported")
+	}
+	return false
+}
+
+func parseHexBytes(str string) ([]byte, error) {
+	str = strings.TrimPrefix(str, "0x")
+	if len(str)%2 == 1 {
+		str = "0" + str
+	}
+	return hex.DecodeString(str)
+}
+
+func parseHexBig(str string) (*big.Int, error) {
+	str = strings.TrimPrefix(str, "0x")
+	if str == "" {
+		return big.NewInt(0), nil
+	}
+	num, ok := new(big.Int).SetString(str, 16)
+	if !ok {
+		return nil, fmt.Errorf("failed to decode hex number '%s'", str)
+	}
+	return num, nil
+}
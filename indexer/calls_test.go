@@ -0,0 +1,103 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/go-web3"
+)
+
+func TestCalls_FlattenCallFrame(t *testing.T) {
+	target := web3.Address{0x1}
+	other := web3.Address{0x2}
+
+	frame := &callFrame{
+		Type:   "CALL",
+		From:   other.String(),
+		To:     target.String(),
+		Value:  "0x10",
+		Input:  "0x01020304",
+		Output: "0x01",
+		Calls: []*callFrame{
+			{
+				// internal call to the target
+				Type:  "CALL",
+				From:  target.String(),
+				To:    target.String(),
+				Input: "0x05",
+			},
+			{
+				// reverted call to the target
+				Type:  "CALL",
+				From:  target.String(),
+				To:    target.String(),
+				Error: "execution reverted",
+			},
+			{
+				// call to another contract
+				Type: "CALL",
+				From: target.String(),
+				To:   other.String(),
+			},
+		},
+	}
+
+	isTarget := func(addr web3.Address) bool {
+		return addr == target
+	}
+	txn := &web3.Transaction{
+		Hash:     web3.Hash{0x1},
+		TxnIndex: 1,
+	}
+
+	calls, err := flattenCallFrame(frame, txn, isTarget, false)
+	assert.NoError(t, err)
+	assert.Len(t, calls, 2)
+
+	assert.False(t, calls[0].Internal)
+	assert.Equal(t, calls[0].Value.Uint64(), uint64(16))
+	assert.Equal(t, calls[0].Input, []byte{0x1, 0x2, 0x3, 0x4})
+	assert.Equal(t, calls[0].Output, []byte{0x1})
+
+	assert.True(t, calls[1].Internal)
+	assert.Equal(t, calls[1].From, target)
+	assert.Equal(t, calls[1].TxHash, txn.Hash)
+}
+
+func TestCalls_DecodeTraces(t *testing.T) {
+	target := web3.Address{0x1}
+	other := web3.Address{0x2}
+
+	newTrace := func(block uint64, callType string, err string) *trace {
+		tr := &trace{
+			Type:            "call",
+			Error:           err,
+			BlockNumber:     block,
+			BlockHash:       web3.Hash{byte(block)}.String(),
+			TransactionHash: web3.Hash{0x10}.String(),
+		}
+		tr.Action.CallType = callType
+		tr.Action.From = other.String()
+		tr.Action.To = target.String()
+		tr.Action.Value = "0x0"
+		tr.Action.Input = "0x01"
+		return tr
+	}
+
+	traces := []*trace{
+		newTrace(1, "call", ""),
+		newTrace(1, "staticcall", ""),
+		newTrace(1, "delegatecall", ""),
+		newTrace(2, "call", "Reverted"),
+		newTrace(3, "call", ""),
+	}
+	actions, err := decodeTraces(traces)
+	assert.NoError(t, err)
+
+	// only the successful calls that run in the context of the contract
+	assert.Len(t, actions, 2)
+	assert.Equal(t, uint64(1), actions[0].BlockNum)
+	assert.Len(t, actions[0].Calls, 1)
+	assert.Equal(t, uint64(3), actions[1].BlockNum)
+	assert.Len(t, actions[1].Calls, 1)
+}
@@ -0,0 +1,218 @@
+package indexer
+
+import (
+	"errors"
+	"time"
+
+	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/eth-indexer/sdk"
+	protosdk "github.com/umbracle/eth-indexer/sdk/proto"
+	"github.com/umbracle/go-web3"
+)
+
+const (
+	// failureBackoff is the initial wait before processing a block
+	// again with the retry policy. It doubles up to failureMaxBackoff.
+	failureBackoff    = time.Second
+	failureMaxBackoff = time.Minute
+)
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	if backoff *= 2; backoff > failureMaxBackoff {
+		backoff = failureMaxBackoff
+	}
+	return backoff
+}
+
+// process runs the handlers of the provider with the action. The errors of
+// the handlers are handled with the failure policy of the provider and the
+// events (or calls) skipped are added to the dead letters of the block diff.
+// The contracts quarantined are added to the provider once the block diff
+// is applied. It must be called with the lock of the provider held.
+func (p *providerSrv) process(track string, act *sdk.Action, blockDiff *BlockDiff) ([]*protosdk.Diff, error) {
+	// quarantined are the contracts quarantined in this block
+	quarantined := map[web3.Address]struct{}{}
+
+	backoff := failureBackoff
+	for {
+		for addr := range p.quarantined {
+			act.RemoveAddress(addr)
+		}
+		for addr := range quarantined {
+			act.RemoveAddress(addr)
+		}
+
+		diffs, evntErr := p.provider.Process(act)
+		if evntErr == nil {
+			return diffs, nil
+		}
+		p.recordFailure(track, evntErr)
+
+		switch p.policy {
+		case sdk.FailureRetry:
+			p.logger.Warn("process the block again", "block", act.BlockNum, "backoff", backoff)
+			if err := p.sleep(backoff); err != nil {
+				return nil, err
+			}
+			backoff = nextBackoff(backoff)
+
+		case sdk.FailureSkip, sdk.FailureQuarantine:
+			letter := deadLetterFromError(p.name, track, evntErr)
+			if p.policy == sdk.FailureQuarantine && evntErr.Address != (web3.Address{}) {
+				letter.Quarantined = true
+				quarantined[evntErr.Address] = struct{}{}
+				p.logger.Warn("contract quarantined", "address", evntErr.Address)
+			}
+			blockDiff.DeadLetters = append(blockDiff.DeadLetters, letter)
+
+			if !act.Skip(evntErr) {
+				if len(act.Events) == 0 && len(act.Calls) == 0 && !act.BlockTick {
+					// the error does not come from the handlers
+					return nil, evntErr
+				}
+				// the error is not caused by a single event, skip the block
+				p.logger.Warn("block skipped", "block", act.BlockNum)
+				act.Events = nil
+				act.Templates = nil
+				act.Calls = nil
+				act.BlockTick = false
+			}
+
+		default:
+			return nil, evntErr
+		}
+	}
+}
+
+// applyDiff applies the diff of the block. With the retry policy the
+// errors are retried with backoff, otherwise, the error is returned.
+// It must be called with the lock of the provider held.
+func (p *providerSrv) applyDiff(state *State, blockDiff *BlockDiff) error {
+	backoff := failureBackoff
+	for {
+		err := state.ApplyDiff(blockDiff, true)
+		if err == nil {
+			p.addQuarantined(blockDiff)
+			return nil
+		}
+		if p.policy != sdk.FailureRetry {
+			return err
+		}
+		p.logger.Warn("failed to apply the diff, retrying", "block", blockDiff.Number, "err", err, "backoff", backoff)
+		if err := p.sleep(backoff); err != nil {
+			return err
+		}
+		backoff = nextBackoff(backoff)
+	}
+}
+
+// errReverted is returned when the provider is reverted by another
+// track while a block is retried without the lock
+var errReverted = errors.New("provider reverted while retrying the block")
+
+// sleep waits for the backoff without the lock of the provider so that
+// its other tracks are not blocked. It returns the error of the provider
+// if it failed meanwhile, or errReverted if it was reverted. It must be
+// called with the lock held.
+func (p *providerSrv) sleep(backoff time.Duration) error {
+	reverts := p.reverts
+
+	p.lock.Unlock()
+	time.Sleep(backoff)
+	p.lock.Lock()
+
+	if p.err != nil {
+		return p.err
+	}
+	if p.reverts != reverts {
+		return errReverted
+	}
+	return nil
+}
+
+// addQuarantined adds the contracts quarantined in the
+// block diff once it has been applied
+func (p *providerSrv) addQuarantined(blockDiff *BlockDiff) {
+	for _, letter := range blockDiff.DeadLetters {
+		if letter.Quarantined {
+			p.quarantined[web3.HexToAddress(letter.Address)] = struct{}{}
+		}
+	}
+}
+
+func (p *providerSrv) recordFailure(track string, evntErr *sdk.ErrorEvent) {
+	p.statusLock.Lock()
+	p.failures++
+	p.lastFailure = evntErr
+	p.statusLock.Unlock()
+
+	p.logger.Error("handler failed",
+		"policy", p.policy,
+		"track", track,
+		"tracker", evntErr.Tracker,
+		"block", evntErr.Block,
+		"tx", evntErr.TxHash,
+		"log", evntErr.LogIndex,
+		"type", evntErr.Type,
+		"err", evntErr.Err,
+	)
+}
+
+// loadQuarantined loads the contracts quarantined by the provider
+func (p *providerSrv) loadQuarantined(state *State) error {
+	addrs, err := state.GetQuarantined(p.name)
+	if err != nil {
+		return err
+	}
+	p.quarantined = map[web3.Address]struct{}{}
+	for _, addr := range addrs {
+		p.quarantined[addr] = struct{}{}
+	}
+	return nil
+}
+
+func deadLetterFromError(provider, track string, evntErr *sdk.ErrorEvent) *proto.DeadLetter {
+	letter := &proto.DeadLetter{
+		Provider:  provider,
+		Track:     track,
+		BlockNum:  evntErr.Block,
+		LogIndex:  evntErr.LogIndex,
+		Tracker:   evntErr.Tracker,
+		ErrorType: evntErr.Type,
+	}
+	if evntErr.TxHash != (web3.Hash{}) {
+		letter.TxHash = evntErr.TxHash.String()
+	}
+	if evntErr.Address != (web3.Address{}) {
+		letter.Address = evntErr.Address.String()
+	}
+	if evntErr.Err != nil {
+		letter.ErrorMsg = evntErr.Err.Error()
+	}
+	return letter
+}
+
+// halt stops the indexer process because of the error
+func (s *Server) halt(err error) {
+	select {
+	case s.haltCh <- err:
+	default:
+	}
+}
+
+// HaltCh returns the channel that receives the error
+// of a provider that halts the indexer
+func (s *Server) HaltCh() <-chan error {
+	return s.haltCh
+}
+
+func resolveFailurePolicy(name string, provider *sdk.Provider, config *Config) (sdk.FailurePolicy, error) {
+	policy := provider.FailurePolicy
+	if override, ok := config.FailurePolicy[name]; ok {
+		policy = override
+	}
+	if policy == "" {
+		return sdk.FailureStop, nil
+	}
+	return sdk.ParseFailurePolicy(string(policy))
+}
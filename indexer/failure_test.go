@@ -0,0 +1,205 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/eth-indexer/sdk"
+	"github.com/umbracle/go-web3"
+	"github.com/umbracle/go-web3/abi"
+	gproto "google.golang.org/protobuf/proto"
+)
+
+func testFailureProvider(t *testing.T, policy sdk.FailurePolicy, bad web3.Address) (*providerSrv, *[]uint64) {
+	event := abi.MustNewEvent("event Ping()")
+
+	handled := []uint64{}
+	provider := &sdk.Provider{
+		Trackers: []*sdk.Tracker{
+			{
+				Type: event,
+				Handler: func(req *sdk.HandlerReq) {
+					if req.Evnt.Address == bad.String() {
+						panic("bad contract")
+					}
+					handled = append(handled, req.Evnt.LogIndex)
+				},
+			},
+		},
+	}
+	assert.NoError(t, provider.Init())
+
+	p := &providerSrv{
+		name:        "test",
+		logger:      hclog.NewNullLogger(),
+		provider:    provider,
+		policy:      policy,
+		quarantined: map[web3.Address]struct{}{},
+	}
+	return p, &handled
+}
+
+func testFailureAction(addrs ...web3.Address) *sdk.Action {
+	event := abi.MustNewEvent("event Ping()")
+
+	act := &sdk.Action{
+		BlockNum: 1,
+	}
+	for indx, addr := range addrs {
+		log := &web3.Log{
+			LogIndex:    uint64(indx),
+			BlockNumber: 1,
+			Address:     addr,
+			Topics:      []web3.Hash{event.ID()},
+		}
+		act.Events = append(act.Events, proto.Event{})
+		gproto.Merge(&act.Events[indx], proto.DecodeEvent(log))
+	}
+	return act
+}
+
+func TestFailure_Skip(t *testing.T) {
+	good, bad := web3.Address{0x1}, web3.Address{0x2}
+
+	p, handled := testFailureProvider(t, sdk.FailureSkip, bad)
+
+	blockDiff := &BlockDiff{}
+	_, err := p.process("track", testFailureAction(good, bad, bad, good), blockDiff)
+	assert.NoError(t, err)
+
+	// the action is processed again after each failure
+	assert.Equal(t, []uint64{0, 3}, (*handled)[len(*handled)-2:])
+	assert.Len(t, blockDiff.DeadLetters, 2)
+	assert.Equal(t, uint64(1), blockDiff.DeadLetters[0].LogIndex)
+	assert.Equal(t, uint64(2), blockDiff.DeadLetters[1].LogIndex)
+	assert.Equal(t, "Ping", blockDiff.DeadLetters[0].Tracker)
+	assert.False(t, blockDiff.DeadLetters[0].Quarantined)
+	assert.Equal(t, uint64(2), p.failures)
+}
+
+func TestFailure_Quarantine(t *testing.T) {
+	good, bad := web3.Address{0x1}, web3.Address{0x2}
+
+	p, handled := testFailureProvider(t, sdk.FailureQuarantine, bad)
+
+	blockDiff := &BlockDiff{}
+	_, err := p.process("track", testFailureAction(good, bad, bad, good), blockDiff)
+	assert.NoError(t, err)
+
+	// only the first event of the contract is recorded
+	assert.Equal(t, []uint64{0, 3}, (*handled)[len(*handled)-2:])
+	assert.Len(t, blockDiff.DeadLetters, 1)
+	assert.True(t, blockDiff.DeadLetters[0].Quarantined)
+	assert.Equal(t, bad.String(), blockDiff.DeadLetters[0].Address)
+
+	// the contract is quarantined once the block diff is applied
+	assert.NotContains(t, p.quarantined, bad)
+	p.addQuarantined(blockDiff)
+	assert.Contains(t, p.quarantined, bad)
+}
+
+func TestFailure_RetryUnlocked(t *testing.T) {
+	good, bad := web3.Address{0x1}, web3.Address{0x2}
+
+	p, _ := testFailureProvider(t, sdk.FailureRetry, bad)
+
+	errCh := make(chan error, 1)
+	go func() {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+
+		_, err := p.process("track", testFailureAction(good, bad), &BlockDiff{})
+		errCh <- err
+	}()
+
+	// the lock is released while waiting to process the block again
+	failed := func() bool {
+		p.statusLock.Lock()
+		defer p.statusLock.Unlock()
+		return p.failures != 0
+	}
+	for !failed() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	p.lock.Lock()
+	p.fail(assert.AnError)
+	p.lock.Unlock()
+
+	select {
+	case err := <-errCh:
+		assert.Equal(t, assert.AnError, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the provider is not stopped")
+	}
+}
+
+func TestFailure_RetryReverted(t *testing.T) {
+	good, bad := web3.Address{0x1}, web3.Address{0x2}
+
+	p, _ := testFailureProvider(t, sdk.FailureRetry, bad)
+
+	errCh := make(chan error, 1)
+	go func() {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+
+		_, err := p.process("track", testFailureAction(good, bad), &BlockDiff{})
+		errCh <- err
+	}()
+
+	failed := func() bool {
+		p.statusLock.Lock()
+		defer p.statusLock.Unlock()
+		return p.failures != 0
+	}
+	for !failed() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// another track reverts the provider while the block is retried
+	p.lock.Lock()
+	p.reverts++
+	p.lock.Unlock()
+
+	select {
+	case err := <-errCh:
+		assert.Equal(t, errReverted, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the revert is not detected")
+	}
+	assert.NoError(t, p.err)
+}
+
+func TestFailure_Stop(t *testing.T) {
+	good, bad := web3.Address{0x1}, web3.Address{0x2}
+
+	p, _ := testFailureProvider(t, sdk.FailureStop, bad)
+
+	blockDiff := &BlockDiff{}
+	_, err := p.process("track", testFailureAction(good, bad), blockDiff)
+	assert.Error(t, err)
+	assert.Len(t, blockDiff.DeadLetters, 0)
+}
+
+func TestFailure_Halt(t *testing.T) {
+	srv := &Server{
+		haltCh: make(chan error, 1),
+	}
+	p := &providerSrv{
+		name:   "test",
+		logger: hclog.NewNullLogger(),
+		policy: sdk.FailureHalt,
+		halt:   srv.halt,
+	}
+	p.fail(assert.AnError)
+
+	select {
+	case err := <-srv.HaltCh():
+		assert.Contains(t, err.Error(), "test")
+	default:
+		t.Fatal("the indexer is not halted")
+	}
+}
@@ -0,0 +1,156 @@
+package indexer
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/umbracle/go-web3"
+)
+
+const (
+	// defaultHeadPollInterval is the period to poll the head of the
+	// chain when the endpoints do not support subscriptions
+	defaultHeadPollInterval = time.Second
+
+	// headWatchdogInterval is the period to poll the head of the chain
+	// while subscribed in case the subscription silently stops
+	headWatchdogInterval = 15 * time.Second
+)
+
+type subscribeFn func(method string, callback func(b []byte)) (func() error, error)
+
+type headProvider interface {
+	GetBlockByNumber(i web3.BlockNumber, full bool) (*web3.Block, error)
+}
+
+// headTracker is the block tracker of the go-web3 tracker for the live
+// tail of the chain. It receives the new heads from a newHeads subscription
+// and it falls back to polling the latest block if the endpoints do not
+// support subscriptions.
+type headTracker struct {
+	logger       hclog.Logger
+	provider     headProvider
+	subscribe    subscribeFn
+	pollInterval time.Duration
+
+	// watchdogInterval is the period to poll the head while subscribed,
+	// if no head is received meanwhile the subscription is renewed
+	watchdogInterval time.Duration
+
+	// head is the number of the last head received
+	head uint64
+}
+
+// Track implements the tracker.BlockTracker interface
+func (h *headTracker) Track(ctx context.Context, handle func(block *web3.Block) error) error {
+	headCh := make(chan *web3.Block, 16)
+
+	watchdogInterval := h.watchdogInterval
+	if watchdogInterval == 0 {
+		watchdogInterval = headWatchdogInterval
+	}
+
+	interval := h.pollInterval
+	cancel := h.subscribeHeads(headCh)
+	if cancel != nil {
+		interval = watchdogInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		// received is set if the subscription sent a head since the last tick
+		var received bool
+
+		var lastBlock *web3.Block
+		for {
+			var block *web3.Block
+			select {
+			case block = <-headCh:
+				received = true
+				if cancel != nil && interval != watchdogInterval {
+					// the subscription works again, stop polling
+					interval = watchdogInterval
+					ticker.Reset(interval)
+				}
+
+			case <-ticker.C:
+				if cancel != nil && interval == watchdogInterval && !received {
+					// the subscription stalled, subscribe again and poll
+					// the head meanwhile. If the subscription is not
+					// available anymore the head is polled from now on.
+					h.logger.Warn("no heads received from the subscription, subscribing again", "interval", watchdogInterval)
+					cancel()
+					cancel = h.subscribeHeads(headCh)
+					interval = h.pollInterval
+					ticker.Reset(interval)
+				}
+				received = false
+
+				var err error
+				if block, err = h.provider.GetBlockByNumber(web3.Latest, false); err != nil || block == nil {
+					h.logger.Error("failed to get the head", "err", err)
+					continue
+				}
+
+			case <-ctx.Done():
+				if cancel != nil {
+					cancel()
+				}
+				return
+			}
+
+			if lastBlock != nil && lastBlock.Hash == block.Hash {
+				continue
+			}
+			h.setHead(block)
+			if err := handle(block); err != nil {
+				h.logger.Error("failed to handle head", "block", block.Number, "err", err)
+				continue
+			}
+			lastBlock = block
+		}
+	}()
+	return nil
+}
+
+// subscribeHeads subscribes to the new heads. It returns the function to
+// cancel the subscription or nil if the subscription is not available.
+func (h *headTracker) subscribeHeads(headCh chan *web3.Block) func() error {
+	cancel, err := h.subscribe("newHeads", func(buf []byte) {
+		block := new(web3.Block)
+		if err := block.UnmarshalJSON(buf); err != nil {
+			h.logger.Error("failed to decode head", "err", err)
+			return
+		}
+		select {
+		case headCh <- block:
+		default:
+			// the tracker backfills the skipped heads from the next one
+		}
+	})
+	if err != nil {
+		h.logger.Info("newHeads subscription not available, polling the head", "interval", h.pollInterval, "err", err)
+		return nil
+	}
+	h.logger.Info("subscribed to newHeads")
+	return cancel
+}
+
+func (h *headTracker) setHead(block *web3.Block) {
+	if block.Number > atomic.LoadUint64(&h.head) {
+		atomic.StoreUint64(&h.head, block.Number)
+	}
+	metricHead.Set(int64(block.Number))
+}
+
+// getHead returns the number of the last head received
+func (h *headTracker) getHead() uint64 {
+	if h == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&h.head)
+}
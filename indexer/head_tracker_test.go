@@ -0,0 +1,143 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/go-web3"
+)
+
+type mockHeadProvider struct {
+	lock  sync.Mutex
+	block *web3.Block
+}
+
+func (m *mockHeadProvider) setHead(num uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.block = &web3.Block{Number: num, Hash: web3.Hash{byte(num)}}
+}
+
+func (m *mockHeadProvider) GetBlockByNumber(i web3.BlockNumber, full bool) (*web3.Block, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.block, nil
+}
+
+func trackHeads(t *testing.T, h *headTracker) (<-chan *web3.Block, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	blockCh := make(chan *web3.Block, 10)
+	err := h.Track(ctx, func(block *web3.Block) error {
+		blockCh <- block
+		return nil
+	})
+	assert.NoError(t, err)
+	return blockCh, cancel
+}
+
+func expectHead(t *testing.T, blockCh <-chan *web3.Block, num uint64) {
+	select {
+	case block := <-blockCh:
+		assert.Equal(t, num, block.Number)
+	case <-time.After(time.Second):
+		t.Fatalf("head %d not received", num)
+	}
+}
+
+func TestHeadTracker_Polling(t *testing.T) {
+	provider := &mockHeadProvider{}
+	provider.setHead(1)
+
+	h := &headTracker{
+		logger:   hclog.NewNullLogger(),
+		provider: provider,
+		subscribe: func(method string, callback func(b []byte)) (func() error, error) {
+			return nil, fmt.Errorf("not supported")
+		},
+		pollInterval: 10 * time.Millisecond,
+	}
+	blockCh, _ := trackHeads(t, h)
+
+	expectHead(t, blockCh, 1)
+
+	// the same head is not handled twice
+	provider.setHead(2)
+	expectHead(t, blockCh, 2)
+	assert.Equal(t, uint64(2), h.getHead())
+}
+
+func TestHeadTracker_Subscription(t *testing.T) {
+	var callback func(b []byte)
+	h := &headTracker{
+		logger:   hclog.NewNullLogger(),
+		provider: &mockHeadProvider{},
+		subscribe: func(method string, cb func(b []byte)) (func() error, error) {
+			assert.Equal(t, "newHeads", method)
+			callback = cb
+			return func() error { return nil }, nil
+		},
+		pollInterval: 10 * time.Millisecond,
+	}
+	blockCh, _ := trackHeads(t, h)
+
+	head := func(num uint64) []byte {
+		block := &web3.Block{Number: num, Hash: web3.Hash{byte(num)}, Difficulty: big.NewInt(0)}
+		buf, err := block.MarshalJSON()
+		assert.NoError(t, err)
+		return buf
+	}
+
+	callback(head(1))
+	expectHead(t, blockCh, 1)
+
+	callback(head(1))
+	callback(head(2))
+	expectHead(t, blockCh, 2)
+	assert.Equal(t, uint64(2), h.getHead())
+}
+
+func TestHeadTracker_Resubscribe(t *testing.T) {
+	provider := &mockHeadProvider{}
+	provider.setHead(1)
+
+	var lock sync.Mutex
+	var subscribed, cancelled int
+
+	h := &headTracker{
+		logger:   hclog.NewNullLogger(),
+		provider: provider,
+		subscribe: func(method string, cb func(b []byte)) (func() error, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			// the subscription never sends a head
+			subscribed++
+			return func() error {
+				lock.Lock()
+				defer lock.Unlock()
+				cancelled++
+				return nil
+			}, nil
+		},
+		pollInterval:     10 * time.Millisecond,
+		watchdogInterval: 50 * time.Millisecond,
+	}
+	blockCh, _ := trackHeads(t, h)
+
+	// the stalled subscription is renewed and the head is polled meanwhile
+	expectHead(t, blockCh, 1)
+	provider.setHead(2)
+	expectHead(t, blockCh, 2)
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, 2, subscribed)
+	assert.Equal(t, 1, cancelled)
+}
@@ -0,0 +1,48 @@
+package indexer
+
+import (
+	"expvar"
+	"net/http"
+	"time"
+
+	"github.com/umbracle/eth-indexer/sdk"
+)
+
+// the metrics are published with expvar under /debug/vars
+var (
+	// metricHead is the number of the last head of the chain
+	metricHead = expvar.NewInt("indexer_head")
+
+	// metricHeadLagBlocks is the number of blocks each track is behind the head
+	metricHeadLagBlocks = expvar.NewMap("indexer_head_lag_blocks")
+
+	// metricHeadLagSeconds is the time between the block timestamp
+	// and the moment its diff is applied for each track
+	metricHeadLagSeconds = expvar.NewMap("indexer_head_lag_seconds")
+)
+
+// updateHeadLag records the lag of the track after applying the block
+func updateHeadLag(track string, head uint64, block *sdk.Block) {
+	if head >= block.Number {
+		lagBlocks := new(expvar.Int)
+		lagBlocks.Set(int64(head - block.Number))
+		metricHeadLagBlocks.Set(track, lagBlocks)
+	}
+
+	lagSeconds := new(expvar.Float)
+	lagSeconds.Set(time.Since(time.Unix(int64(block.Timestamp), 0)).Seconds())
+	metricHeadLagSeconds.Set(track, lagSeconds)
+}
+
+// serveHTTP serves the metrics and the status of the indexer in the address
+func (s *Server) serveHTTP(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/v1/status", s.handleStatus)
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			s.logger.Error("failed to serve http", "err", err)
+		}
+	}()
+}
@@ -1,5 +1,6 @@
-/*
-CREATE TABLE IF NOT EXISTS block (
-
-)
-*/
\ No newline at end of file
+CREATE TABLE IF NOT EXISTS blocks (
+    number      numeric UNIQUE,
+    hash        text,
+    parent_hash text,
+    timestamp   numeric
+);
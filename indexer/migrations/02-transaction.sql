@@ -1,6 +1,5 @@
-/*
 CREATE TABLE IF NOT EXISTS transactions (
-    hash        text,
+    hash        text UNIQUE,
     nonce       numeric,
     block_hash  text,
     block_num   numeric,
@@ -11,5 +10,6 @@ CREATE TABLE IF NOT EXISTS transactions (
     gas         numeric,
     gas_price   numeric,
     input       text
-)
-*/
\ No newline at end of file
+);
+
+CREATE INDEX IF NOT EXISTS transactions_from_addr ON transactions(from_addr);
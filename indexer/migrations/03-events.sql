@@ -1,18 +1,17 @@
-/*
 CREATE TABLE IF NOT EXISTS events (
+    track       text,
+    log_index   numeric,
+    tx_index    numeric,
+    tx_hash     text,
+    block_num   numeric,
+    block_hash  text,
+    address     text,
+    topicid     text,
+    topics      text,
+    data        text,
     event       text,
-	indx 		numeric,
-	full_indx   numeric,
-	tx_index 	numeric,
-	tx_hash 	text,
-	block_num 	numeric,
-	block_hash 	text,
-	address 	text,
-	topicid     text,
-	topics 		text,
-	data 		text,
-    removed     boolean
+    removed     boolean,
+    UNIQUE (track, block_num, log_index)
 );
 
 CREATE INDEX IF NOT EXISTS events_block_num ON events(block_num);
-*/
\ No newline at end of file
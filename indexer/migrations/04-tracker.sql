@@ -7,5 +7,40 @@ CREATE TABLE IF NOT EXISTS tracks (
     lastBlockHash text,
     lastBlockNum  numeric,
     startBlock    numeric,
-    synced        boolean
+    synced        boolean,
+    template      text NOT NULL DEFAULT '',
+    provider      text NOT NULL DEFAULT '',
+    calls         boolean NOT NULL DEFAULT false,
+    blocks        boolean NOT NULL DEFAULT false
+);
+
+-- tracks created before the dynamic sources
+ALTER TABLE tracks ADD COLUMN IF NOT EXISTS template text NOT NULL DEFAULT '';
+
+-- tracks created before running several providers
+ALTER TABLE tracks ADD COLUMN IF NOT EXISTS provider text NOT NULL DEFAULT '';
+
+-- tracks created before the call trackers
+ALTER TABLE tracks ADD COLUMN IF NOT EXISTS calls boolean NOT NULL DEFAULT false;
+
+-- tracks created before the block trackers
+ALTER TABLE tracks ADD COLUMN IF NOT EXISTS blocks boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS tracker_kv (
+    key         text UNIQUE,
+    val         text
+);
+
+CREATE TABLE IF NOT EXISTS tracker_logs (
+    entry       text,
+    indx        numeric,
+    log_index   numeric,
+    tx_index    numeric,
+    tx_hash     text,
+    block_num   numeric,
+    block_hash  text,
+    address     text,
+    topics      text,
+    data        text,
+    UNIQUE (entry, indx)
 );
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS journal (
+    indx        bigserial,
+    track       text,
+    block_num   numeric,
+    tbl         text,
+    creation    boolean,
+    keys        text,
+    vals        text
+);
+
+CREATE INDEX IF NOT EXISTS journal_track_block_num ON journal(track, block_num);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS dead_letters (
+    provider    text,
+    track       text,
+    block_num   numeric,
+    tx_hash     text,
+    log_index   numeric,
+    tracker     text,
+    address     text,
+    error_type  text,
+    error_msg   text,
+    quarantined boolean
+);
+
+CREATE INDEX IF NOT EXISTS dead_letters_provider ON dead_letters(provider);
+CREATE INDEX IF NOT EXISTS dead_letters_track_block_num ON dead_letters(track, block_num);
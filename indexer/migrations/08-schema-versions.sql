@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS schema_versions (
+    tbl       text PRIMARY KEY,
+    version   numeric,
+    signature text
+);
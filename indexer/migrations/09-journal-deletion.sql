@@ -0,0 +1 @@
+ALTER TABLE journal ADD COLUMN IF NOT EXISTS deletion boolean DEFAULT false;
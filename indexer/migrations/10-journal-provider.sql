@@ -0,0 +1,8 @@
+ALTER TABLE journal ADD COLUMN IF NOT EXISTS provider text;
+ALTER TABLE journal ADD COLUMN IF NOT EXISTS block_hash text;
+
+-- the entries journaled before are reverted per provider as well
+UPDATE journal SET provider = tracks.provider FROM tracks WHERE journal.track = tracks.name AND journal.provider IS NULL;
+UPDATE journal SET block_hash = blocks.hash FROM blocks WHERE journal.block_num = blocks.number AND journal.block_hash IS NULL;
+
+CREATE INDEX IF NOT EXISTS journal_provider_block_num ON journal(provider, block_num);
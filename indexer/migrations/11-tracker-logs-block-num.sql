@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS tracker_logs_entry_block_num ON tracker_logs(entry, block_num);
@@ -0,0 +1,93 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/eth-indexer/sdk"
+)
+
+// polledTrackDepth is the number of blocks behind the head at which the polled
+// tracks (i.e. calls) are indexed. Unlike the logs, reorgs are not detected.
+const polledTrackDepth = 10
+
+// polledTrackPeriod is the period to query for new blocks
+var polledTrackPeriod = 5 * time.Second
+
+var errProviderFailed = errors.New("provider failed")
+
+// fetchActionsFn returns the actions between the blocks 'from' and 'to'
+type fetchActionsFn func(from, to uint64) ([]*sdk.Action, error)
+
+// startPolledTrack starts a track that polls the chain for new blocks
+// and fetches the actions with the given function.
+func (t *trackerSrv) startPolledTrack(track *proto.Track, p *providerSrv, fetch fetchActionsFn) {
+	go func() {
+		for {
+			if err := t.syncPolled(track, p, fetch); err != nil {
+				if errors.Is(err, errProviderFailed) {
+					// the provider failed, stop the track
+					return
+				}
+				t.logger.Error("failed to sync", "track", track.Name, "err", err)
+			}
+			time.Sleep(polledTrackPeriod)
+		}
+	}()
+}
+
+// syncPolled processes the actions from the cursor of the track up to the head
+func (t *trackerSrv) syncPolled(track *proto.Track, p *providerSrv, fetch fetchActionsFn) error {
+	head, err := t.provider.Eth().BlockNumber()
+	if err != nil {
+		return err
+	}
+	progress := t.getProgress(track.Name)
+	progress.setHead(head)
+
+	// the reorgs are not detected, stay below the deepest one the logs revert
+	depth := uint64(polledTrackDepth)
+	if t.srv.state.journalDepth > depth {
+		depth = t.srv.state.journalDepth
+	}
+	if head < depth {
+		return nil
+	}
+	head = head - depth
+
+	from := track.StartBlock + 1
+	if track.LastBlockNum >= from {
+		from = track.LastBlockNum + 1
+	}
+	for from <= head {
+		to := from + t.srv.config.BatchSize - 1
+		if to > head {
+			to = head
+		}
+
+		actions, err := fetch(from, to)
+		if err != nil {
+			return err
+		}
+		if len(actions) != 0 {
+			if err := t.processActions(track, actions, p); errors.Is(err, errReverted) {
+				// sync again from the cursor of the track
+				return err
+			} else if err != nil {
+				return fmt.Errorf("%w: %v", errProviderFailed, err)
+			}
+		}
+
+		// move the cursor over the blocks without actions
+		track.LastBlockNum = to
+		track.LastBlockHash = ""
+		if err := t.srv.state.UpdateTrackCursor(track); err != nil {
+			return err
+		}
+		progress.setBlock(to)
+		from = to + 1
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package indexer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// progressLogInterval is the period to log the progress of the tracks
+var progressLogInterval = 30 * time.Second
+
+// trackProgress is the sync progress of a track since the indexer started
+type trackProgress struct {
+	lock sync.Mutex
+
+	name     string
+	provider string
+
+	// startBlock is the first block of the track
+	startBlock uint64
+
+	// block is the last block indexed and head the last
+	// head of the chain seen by the track (polled tracks)
+	block uint64
+	head  uint64
+
+	// events is the number of events (and calls) processed
+	// and diffs the number of state changes applied
+	events uint64
+	diffs  uint64
+
+	// the throughput is measured since the track started
+	initBlock uint64
+	initTime  time.Time
+
+	// synced is set once the track reaches the head
+	synced bool
+}
+
+func newTrackProgress(name, provider string, startBlock, block uint64) *trackProgress {
+	if block < startBlock {
+		block = startBlock
+	}
+	return &trackProgress{
+		name:       name,
+		provider:   provider,
+		startBlock: startBlock,
+		block:      block,
+		initBlock:  block,
+		initTime:   time.Now(),
+	}
+}
+
+// setBlock moves the last block indexed (i.e. blocks without events)
+func (p *trackProgress) setBlock(num uint64) {
+	p.lock.Lock()
+	if num > p.block {
+		p.block = num
+	}
+	p.lock.Unlock()
+}
+
+// setHead records the head of the chain seen by the track
+func (p *trackProgress) setHead(num uint64) {
+	p.lock.Lock()
+	if num > p.head {
+		p.head = num
+	}
+	p.lock.Unlock()
+}
+
+// add records a block applied with its events and diffs
+func (p *trackProgress) add(num uint64, events, diffs int) {
+	p.lock.Lock()
+	if num > p.block {
+		p.block = num
+	}
+	p.events += uint64(events)
+	p.diffs += uint64(diffs)
+	p.lock.Unlock()
+}
+
+// TrackStatus is the sync progress of a track
+type TrackStatus struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+
+	// Block is the last block indexed and Head the head of the chain
+	Block uint64 `json:"block"`
+	Head  uint64 `json:"head"`
+
+	// Percent is the share of blocks indexed since the start block
+	Percent float64 `json:"percent"`
+	Synced  bool    `json:"synced"`
+
+	// Events and Diffs are the number of events processed and
+	// state changes applied since the indexer started
+	Events uint64 `json:"events"`
+	Diffs  uint64 `json:"diffs"`
+
+	// BlocksPerSec and EventsPerSec are the throughput since the
+	// indexer started and ETA the time left to reach the head
+	BlocksPerSec float64       `json:"blocksPerSec"`
+	EventsPerSec float64       `json:"eventsPerSec"`
+	ETA          time.Duration `json:"eta"`
+}
+
+// status returns the progress of the track with the given head of the chain
+func (p *trackProgress) status(head uint64, now time.Time) *TrackStatus {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.head > head {
+		head = p.head
+	}
+	status := &TrackStatus{
+		Name:     p.name,
+		Provider: p.provider,
+		Block:    p.block,
+		Head:     head,
+		Events:   p.events,
+		Diffs:    p.diffs,
+	}
+	if head == 0 {
+		// the head is not known yet
+		return status
+	}
+
+	// the polled tracks are indexed some blocks behind the head
+	status.Synced = p.block+polledTrackDepth >= head
+	if status.Synced || head <= p.startBlock {
+		status.Percent = 100
+	} else if p.block > p.startBlock {
+		status.Percent = 100 * float64(p.block-p.startBlock) / float64(head-p.startBlock)
+	}
+
+	if elapsed := now.Sub(p.initTime).Seconds(); elapsed > 0 {
+		status.BlocksPerSec = float64(p.block-p.initBlock) / elapsed
+		status.EventsPerSec = float64(p.events) / elapsed
+	}
+	if !status.Synced && status.BlocksPerSec > 0 {
+		eta := float64(head-p.block) / status.BlocksPerSec
+		status.ETA = time.Duration(eta * float64(time.Second)).Round(time.Second)
+	}
+	return status
+}
+
+// startProgress starts the progress of the track
+func (t *trackerSrv) startProgress(name, provider string, startBlock, block uint64) {
+	t.tracksLock.Lock()
+	defer t.tracksLock.Unlock()
+
+	t.progress[name] = newTrackProgress(name, provider, startBlock, block)
+}
+
+// getProgress returns the progress of the track (if any)
+func (t *trackerSrv) getProgress(name string) *trackProgress {
+	t.tracksLock.Lock()
+	defer t.tracksLock.Unlock()
+
+	if p, ok := t.progress[name]; ok {
+		return p
+	}
+	// the progress is not tracked, return a detached one
+	// to avoid the nil checks of the callers
+	return &trackProgress{name: name}
+}
+
+// trackStatus returns the progress of the tracks sorted by name
+func (t *trackerSrv) trackStatus() []*TrackStatus {
+	t.tracksLock.Lock()
+	progress := make([]*trackProgress, 0, len(t.progress))
+	for _, p := range t.progress {
+		progress = append(progress, p)
+	}
+	t.tracksLock.Unlock()
+
+	head := t.head.getHead()
+	now := time.Now()
+
+	res := []*TrackStatus{}
+	for _, p := range progress {
+		res = append(res, p.status(head, now))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// logProgress logs periodically the progress of the tracks that
+// are not synced and when each track reaches the head of the chain
+func (t *trackerSrv) logProgress() {
+	ticker := time.NewTicker(progressLogInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, status := range t.trackStatus() {
+			if status.Head == 0 {
+				continue
+			}
+			if status.Synced {
+				p := t.getProgress(status.Name)
+				p.lock.Lock()
+				first := !p.synced
+				p.synced = true
+				p.lock.Unlock()
+
+				if first {
+					t.logger.Info("track synced to the head", "track", status.Name, "block", status.Block, "head", status.Head)
+				}
+				continue
+			}
+			t.logger.Info("sync progress",
+				"track", status.Name,
+				"block", status.Block,
+				"head", status.Head,
+				"percent", fmt.Sprintf("%.2f", status.Percent),
+				"events", status.Events,
+				"diffs", status.Diffs,
+				"blocks/s", fmt.Sprintf("%.2f", status.BlocksPerSec),
+				"events/s", fmt.Sprintf("%.2f", status.EventsPerSec),
+				"eta", status.ETA,
+			)
+		}
+	}
+}
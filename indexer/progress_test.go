@@ -0,0 +1,69 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress_Status(t *testing.T) {
+	p := newTrackProgress("a", "provider", 1000, 0)
+	assert.Equal(t, uint64(1000), p.block)
+
+	// the head is not known yet
+	status := p.status(0, p.initTime.Add(time.Second))
+	assert.False(t, status.Synced)
+	assert.Zero(t, status.Percent)
+
+	// 100 blocks with 10 events and 20 diffs in 10 seconds
+	p.add(1050, 10, 20)
+	p.setBlock(1100)
+
+	status = p.status(2000, p.initTime.Add(10*time.Second))
+	assert.Equal(t, uint64(1100), status.Block)
+	assert.Equal(t, uint64(2000), status.Head)
+	assert.Equal(t, 10.0, status.Percent)
+	assert.Equal(t, uint64(10), status.Events)
+	assert.Equal(t, uint64(20), status.Diffs)
+	assert.Equal(t, 10.0, status.BlocksPerSec)
+	assert.Equal(t, 1.0, status.EventsPerSec)
+	assert.Equal(t, 90*time.Second, status.ETA)
+	assert.False(t, status.Synced)
+
+	// the block does not move backwards
+	p.setBlock(1090)
+	assert.Equal(t, uint64(1100), p.block)
+}
+
+func TestProgress_Synced(t *testing.T) {
+	p := newTrackProgress("a", "provider", 0, 100)
+
+	// the polled tracks are synced some blocks behind the head
+	p.setHead(100 + polledTrackDepth)
+
+	status := p.status(0, p.initTime.Add(time.Second))
+	assert.True(t, status.Synced)
+	assert.Equal(t, 100.0, status.Percent)
+	assert.Zero(t, status.ETA)
+}
+
+func TestProgress_TrackStatus(t *testing.T) {
+	tt := &trackerSrv{
+		progress: map[string]*trackProgress{},
+	}
+	tt.startProgress("b", "provider", 0, 10)
+	tt.startProgress("a", "provider", 0, 20)
+
+	tt.getProgress("a").add(30, 1, 1)
+
+	// the progress of an unknown track is discarded
+	tt.getProgress("c").add(30, 1, 1)
+
+	res := tt.trackStatus()
+	assert.Len(t, res, 2)
+	assert.Equal(t, "a", res[0].Name)
+	assert.Equal(t, uint64(30), res[0].Block)
+	assert.Equal(t, "b", res[1].Name)
+	assert.Equal(t, uint64(10), res[1].Block)
+}
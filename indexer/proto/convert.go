@@ -3,6 +3,7 @@ package proto
 import (
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/umbracle/go-web3"
@@ -21,6 +22,7 @@ func decodeHex(str string) ([]byte, error) {
 
 func (e *Event) ToLog() (*web3.Log, error) {
 	log := &web3.Log{}
+	log.LogIndex = e.LogIndex
 	log.TransactionIndex = e.TxIndex
 	if err := log.TransactionHash.UnmarshalText([]byte(e.TxHash)); err != nil {
 		return nil, err
@@ -82,7 +84,6 @@ func DecodeEvent(log *web3.Log) *Event {
 	return evnt
 }
 
-/*
 func (t *Transaction) ToTxn() (*web3.Transaction, error) {
 	txn := &web3.Transaction{}
 	if err := txn.Hash.UnmarshalText([]byte(t.Hash)); err != nil {
@@ -92,9 +93,11 @@ func (t *Transaction) ToTxn() (*web3.Transaction, error) {
 		return nil, err
 	}
 	if t.ToAddr != "" {
-		if err := txn.To.UnmarshalText([]byte(t.ToAddr)); err != nil {
+		to := web3.Address{}
+		if err := to.UnmarshalText([]byte(t.ToAddr)); err != nil {
 			return nil, err
 		}
+		txn.To = &to
 	}
 	if t.Input != "" {
 		buf, err := decodeHex(t.Input)
@@ -109,7 +112,7 @@ func (t *Transaction) ToTxn() (*web3.Transaction, error) {
 	txn.Gas = t.Gas
 	value, ok := new(big.Int).SetString(t.Value, 10)
 	if !ok {
-		return nil, fmt.Errorf("cannot decode difficulty")
+		return nil, fmt.Errorf("cannot decode value")
 	}
 	txn.Value = value
 	txn.Nonce = t.Nonce
@@ -128,7 +131,6 @@ func DecodeTransaction(txn *web3.Transaction) *Transaction {
 		Input:     "0x" + hex.EncodeToString(txn.Input),
 		GasPrice:  txn.GasPrice,
 		Gas:       txn.Gas,
-		Value:     txn.Value.String(),
 		Nonce:     txn.Nonce,
 		TxIndex:   txn.TxnIndex,
 		BlockHash: txn.BlockHash.String(),
@@ -137,6 +139,10 @@ func DecodeTransaction(txn *web3.Transaction) *Transaction {
 	if txn.To != nil {
 		obj.ToAddr = txn.To.String()
 	}
+	if txn.Value != nil {
+		obj.Value = txn.Value.String()
+	} else {
+		obj.Value = "0"
+	}
 	return obj
 }
-*/
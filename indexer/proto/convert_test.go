@@ -0,0 +1,37 @@
+package proto
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/go-web3"
+)
+
+func TestConvert_Transaction(t *testing.T) {
+	to := web3.Address{0x2}
+	txn := &web3.Transaction{
+		Hash:        web3.Hash{0x1},
+		From:        web3.Address{0x1},
+		To:          &to,
+		Input:       []byte{0x1, 0x2},
+		GasPrice:    10,
+		Gas:         20,
+		Value:       big.NewInt(30),
+		Nonce:       1,
+		BlockHash:   web3.Hash{0x2},
+		BlockNumber: 2,
+		TxnIndex:    3,
+	}
+
+	res, err := DecodeTransaction(txn).ToTxn()
+	assert.NoError(t, err)
+	assert.Equal(t, txn, res)
+
+	// contract creation
+	txn.To = nil
+
+	res, err = DecodeTransaction(txn).ToTxn()
+	assert.NoError(t, err)
+	assert.Nil(t, res.To)
+}
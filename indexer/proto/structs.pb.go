@@ -168,10 +168,13 @@ type Track struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	// comma separated list of addresses
 	// @inject_tag: db:"from_addr"
 	FromAddr string `protobuf:"bytes,1,opt,name=fromAddr,proto3" json:"fromAddr,omitempty" db:"from_addr"`
+	// comma separated list of addresses
 	// @inject_tag: db:"to_addr"
 	ToAddr string `protobuf:"bytes,2,opt,name=toAddr,proto3" json:"toAddr,omitempty" db:"to_addr"`
+	// comma separated list of topics
 	// @inject_tag: db:"topic"
 	Topic string `protobuf:"bytes,3,opt,name=topic,proto3" json:"topic,omitempty" db:"topic"`
 	// @inject_tag: db:"name"
@@ -184,6 +187,16 @@ type Track struct {
 	StartBlock uint64 `protobuf:"varint,7,opt,name=startBlock,proto3" json:"startBlock,omitempty" db:"startblock"`
 	// @inject_tag: db:"synced"
 	Synced bool `protobuf:"varint,8,opt,name=synced,proto3" json:"synced,omitempty" db:"synced"`
+	// @inject_tag: db:"template"
+	Template string `protobuf:"bytes,9,opt,name=template,proto3" json:"template,omitempty" db:"template"`
+	// @inject_tag: db:"provider"
+	Provider string `protobuf:"bytes,10,opt,name=provider,proto3" json:"provider,omitempty" db:"provider"`
+	// calls is true if the track indexes the calls to the contracts
+	// @inject_tag: db:"calls"
+	Calls bool `protobuf:"varint,11,opt,name=calls,proto3" json:"calls,omitempty" db:"calls"`
+	// blocks is true if the track runs the block trackers
+	// @inject_tag: db:"blocks"
+	Blocks bool `protobuf:"varint,12,opt,name=blocks,proto3" json:"blocks,omitempty" db:"blocks"`
 }
 
 func (x *Track) Reset() {
@@ -274,6 +287,303 @@ func (x *Track) GetSynced() bool {
 	return false
 }
 
+func (x *Track) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *Track) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Track) GetCalls() bool {
+	if x != nil {
+		return x.Calls
+	}
+	return false
+}
+
+func (x *Track) GetBlocks() bool {
+	if x != nil {
+		return x.Blocks
+	}
+	return false
+}
+
+type Transaction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// @inject_tag: db:"hash"
+	Hash string `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty" db:"hash"`
+	// @inject_tag: db:"nonce"
+	Nonce uint64 `protobuf:"varint,2,opt,name=nonce,proto3" json:"nonce,omitempty" db:"nonce"`
+	// @inject_tag: db:"block_hash"
+	BlockHash string `protobuf:"bytes,3,opt,name=blockHash,proto3" json:"blockHash,omitempty" db:"block_hash"`
+	// @inject_tag: db:"block_num"
+	BlockNum uint64 `protobuf:"varint,4,opt,name=blockNum,proto3" json:"blockNum,omitempty" db:"block_num"`
+	// @inject_tag: db:"tx_index"
+	TxIndex uint64 `protobuf:"varint,5,opt,name=txIndex,proto3" json:"txIndex,omitempty" db:"tx_index"`
+	// @inject_tag: db:"from_addr"
+	FromAddr string `protobuf:"bytes,6,opt,name=fromAddr,proto3" json:"fromAddr,omitempty" db:"from_addr"`
+	// @inject_tag: db:"to_addr"
+	ToAddr string `protobuf:"bytes,7,opt,name=toAddr,proto3" json:"toAddr,omitempty" db:"to_addr"`
+	// @inject_tag: db:"value"
+	Value string `protobuf:"bytes,8,opt,name=value,proto3" json:"value,omitempty" db:"value"`
+	// @inject_tag: db:"gas"
+	Gas uint64 `protobuf:"varint,9,opt,name=gas,proto3" json:"gas,omitempty" db:"gas"`
+	// @inject_tag: db:"gas_price"
+	GasPrice uint64 `protobuf:"varint,10,opt,name=gasPrice,proto3" json:"gasPrice,omitempty" db:"gas_price"`
+	// @inject_tag: db:"input"
+	Input string `protobuf:"bytes,11,opt,name=input,proto3" json:"input,omitempty" db:"input"`
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_indexer_proto_structs_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_structs_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_structs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Transaction) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Transaction) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *Transaction) GetBlockHash() string {
+	if x != nil {
+		return x.BlockHash
+	}
+	return ""
+}
+
+func (x *Transaction) GetBlockNum() uint64 {
+	if x != nil {
+		return x.BlockNum
+	}
+	return 0
+}
+
+func (x *Transaction) GetTxIndex() uint64 {
+	if x != nil {
+		return x.TxIndex
+	}
+	return 0
+}
+
+func (x *Transaction) GetFromAddr() string {
+	if x != nil {
+		return x.FromAddr
+	}
+	return ""
+}
+
+func (x *Transaction) GetToAddr() string {
+	if x != nil {
+		return x.ToAddr
+	}
+	return ""
+}
+
+func (x *Transaction) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Transaction) GetGas() uint64 {
+	if x != nil {
+		return x.Gas
+	}
+	return 0
+}
+
+func (x *Transaction) GetGasPrice() uint64 {
+	if x != nil {
+		return x.GasPrice
+	}
+	return 0
+}
+
+func (x *Transaction) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+type DeadLetter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// @inject_tag: db:"provider"
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty" db:"provider"`
+	// @inject_tag: db:"track"
+	Track string `protobuf:"bytes,2,opt,name=track,proto3" json:"track,omitempty" db:"track"`
+	// @inject_tag: db:"block_num"
+	BlockNum uint64 `protobuf:"varint,3,opt,name=blockNum,proto3" json:"blockNum,omitempty" db:"block_num"`
+	// @inject_tag: db:"tx_hash"
+	TxHash string `protobuf:"bytes,4,opt,name=txHash,proto3" json:"txHash,omitempty" db:"tx_hash"`
+	// @inject_tag: db:"log_index"
+	LogIndex uint64 `protobuf:"varint,5,opt,name=logIndex,proto3" json:"logIndex,omitempty" db:"log_index"`
+	// @inject_tag: db:"tracker"
+	Tracker string `protobuf:"bytes,6,opt,name=tracker,proto3" json:"tracker,omitempty" db:"tracker"`
+	// address of the contract that emitted the event or received the call
+	// @inject_tag: db:"address"
+	Address string `protobuf:"bytes,7,opt,name=address,proto3" json:"address,omitempty" db:"address"`
+	// @inject_tag: db:"error_type"
+	ErrorType string `protobuf:"bytes,8,opt,name=errorType,proto3" json:"errorType,omitempty" db:"error_type"`
+	// @inject_tag: db:"error_msg"
+	ErrorMsg string `protobuf:"bytes,9,opt,name=errorMsg,proto3" json:"errorMsg,omitempty" db:"error_msg"`
+	// quarantined is true if the events of the contract are skipped
+	// @inject_tag: db:"quarantined"
+	Quarantined bool `protobuf:"varint,10,opt,name=quarantined,proto3" json:"quarantined,omitempty" db:"quarantined"`
+}
+
+func (x *DeadLetter) Reset() {
+	*x = DeadLetter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_indexer_proto_structs_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeadLetter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadLetter) ProtoMessage() {}
+
+func (x *DeadLetter) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_structs_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadLetter.ProtoReflect.Descriptor instead.
+func (*DeadLetter) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_structs_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DeadLetter) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetTrack() string {
+	if x != nil {
+		return x.Track
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetBlockNum() uint64 {
+	if x != nil {
+		return x.BlockNum
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetLogIndex() uint64 {
+	if x != nil {
+		return x.LogIndex
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetTracker() string {
+	if x != nil {
+		return x.Tracker
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetErrorType() string {
+	if x != nil {
+		return x.ErrorType
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetErrorMsg() string {
+	if x != nil {
+		return x.ErrorMsg
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetQuarantined() bool {
+	if x != nil {
+		return x.Quarantined
+	}
+	return false
+}
+
 var File_indexer_proto_structs_proto protoreflect.FileDescriptor
 
 var file_indexer_proto_structs_proto_rawDesc = []byte{
@@ -297,7 +607,7 @@ var file_indexer_proto_structs_proto_rawDesc = []byte{
 	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18,
 	0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07,
 	0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72,
-	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x22, 0xe7, 0x01, 0x0a, 0x05, 0x54, 0x72, 0x61, 0x63, 0x6b,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x22, 0xcd, 0x02, 0x0a, 0x05, 0x54, 0x72, 0x61, 0x63, 0x6b,
 	0x12, 0x1a, 0x0a, 0x08, 0x66, 0x72, 0x6f, 0x6d, 0x41, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x08, 0x66, 0x72, 0x6f, 0x6d, 0x41, 0x64, 0x64, 0x72, 0x12, 0x16, 0x0a, 0x06,
 	0x74, 0x6f, 0x41, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x6f,
@@ -312,8 +622,50 @@ var file_indexer_proto_structs_proto_rawDesc = []byte{
 	0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x73, 0x74,
 	0x61, 0x72, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6e, 0x63,
 	0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x79, 0x6e, 0x63, 0x65, 0x64,
-	0x42, 0x10, 0x5a, 0x0e, 0x2f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x12, 0x1a, 0x0a, 0x08, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08,
+	0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x61, 0x6c, 0x6c,
+	0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x63, 0x61, 0x6c, 0x6c, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22, 0x99, 0x02, 0x0a, 0x0b, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f,
+	0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65,
+	0x12, 0x1c, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1a,
+	0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x78,
+	0x49, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x74, 0x78, 0x49,
+	0x6e, 0x64, 0x65, 0x78, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x72, 0x6f, 0x6d, 0x41, 0x64, 0x64, 0x72,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x72, 0x6f, 0x6d, 0x41, 0x64, 0x64, 0x72,
+	0x12, 0x16, 0x0a, 0x06, 0x74, 0x6f, 0x41, 0x64, 0x64, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x74, 0x6f, 0x41, 0x64, 0x64, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x10,
+	0x0a, 0x03, 0x67, 0x61, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x67, 0x61, 0x73,
+	0x12, 0x1a, 0x0a, 0x08, 0x67, 0x61, 0x73, 0x50, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x08, 0x67, 0x61, 0x73, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x69, 0x6e, 0x70,
+	0x75, 0x74, 0x22, 0x9e, 0x02, 0x0a, 0x0a, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65,
+	0x72, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x14, 0x0a,
+	0x05, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x72,
+	0x61, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x12,
+	0x16, 0x0a, 0x06, 0x74, 0x78, 0x48, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x74, 0x78, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x6f, 0x67, 0x49, 0x6e,
+	0x64, 0x65, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6c, 0x6f, 0x67, 0x49, 0x6e,
+	0x64, 0x65, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x12, 0x18, 0x0a,
+	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x54, 0x79, 0x70, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x73,
+	0x67, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x73,
+	0x67, 0x12, 0x20, 0x0a, 0x0b, 0x71, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x64,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x71, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69,
+	0x6e, 0x65, 0x64, 0x42, 0x10, 0x5a, 0x0e, 0x2f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x65, 0x72, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -328,10 +680,12 @@ func file_indexer_proto_structs_proto_rawDescGZIP() []byte {
 	return file_indexer_proto_structs_proto_rawDescData
 }
 
-var file_indexer_proto_structs_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_indexer_proto_structs_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_indexer_proto_structs_proto_goTypes = []interface{}{
-	(*Event)(nil), // 0: proto.Event
-	(*Track)(nil), // 1: proto.Track
+	(*Event)(nil),       // 0: proto.Event
+	(*Track)(nil),       // 1: proto.Track
+	(*Transaction)(nil), // 2: proto.Transaction
+	(*DeadLetter)(nil),  // 3: proto.DeadLetter
 }
 var file_indexer_proto_structs_proto_depIdxs = []int32{
 	0, // [0:0] is the sub-list for method output_type
@@ -371,6 +725,30 @@ func file_indexer_proto_structs_proto_init() {
 				return nil
 			}
 		}
+		file_indexer_proto_structs_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Transaction); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_indexer_proto_structs_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeadLetter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -378,7 +756,7 @@ func file_indexer_proto_structs_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_indexer_proto_structs_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,156 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/eth-indexer/providers"
+	"github.com/umbracle/eth-indexer/sdk"
+	gproto "google.golang.org/protobuf/proto"
+)
+
+// ErrReindexUnsupported is returned when reindexing a provider with call or
+// block trackers. Only the logs are archived, the calls and the block ticks
+// cannot be replayed.
+var ErrReindexUnsupported = errors.New("the calls and the block ticks are not archived")
+
+// checkReindex returns an error if the provider cannot be replayed from the archive
+func checkReindex(name string) error {
+	factory, ok := providers.BuiltinProviders[name]
+	if !ok {
+		return fmt.Errorf("provider '%s' not found", name)
+	}
+	provider := factory()
+	if err := provider.Init(); err != nil {
+		return fmt.Errorf("failed to init provider '%s': %v", name, err)
+	}
+	if len(provider.CallTrackers) != 0 || len(provider.BlockTrackers) != 0 {
+		return fmt.Errorf("provider '%s' has call or block trackers: %w", name, ErrReindexUnsupported)
+	}
+	return nil
+}
+
+// reindex replays the handlers of the provider with the logs stored in
+// the events archive. The tables of the provider are already empty. The
+// logs of all the tracks in a block are replayed in a single action in
+// the order of the chain.
+func (s *Server) reindex(p *providerSrv) error {
+	tracks, err := s.state.GetTracks(p.name)
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	templates := map[string]string{}
+	for _, track := range tracks {
+		names = append(names, track.Name)
+		templates[track.Name] = track.Template
+	}
+	if len(names) == 0 {
+		p.logger.Info("nothing to reindex")
+		return nil
+	}
+
+	p.logger.Info("reindex", "tracks", len(names))
+
+	var act *sdk.Action
+	var actTracks []string
+	var num uint64
+
+	flush := func() error {
+		if act == nil {
+			return nil
+		}
+		if err := s.reindexAction(p, act, actTracks); err != nil {
+			return err
+		}
+		num++
+		if num%10000 == 0 {
+			p.logger.Info("reindex progress", "block", act.BlockNum, "actions", num)
+		}
+		act = nil
+		return nil
+	}
+
+	err = s.state.IterateEvents(names, func(track string, evnt *proto.Event) error {
+		if act != nil && act.BlockNum != evnt.BlockNum {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if act == nil {
+			act = &sdk.Action{
+				BlockNum:  evnt.BlockNum,
+				Templates: []string{},
+			}
+			if err := act.BlockHash.UnmarshalText([]byte(evnt.BlockHash)); err != nil {
+				return err
+			}
+			actTracks = nil
+		}
+		act.Events = append(act.Events, proto.Event{})
+		gproto.Merge(&act.Events[len(act.Events)-1], evnt)
+		act.Templates = append(act.Templates, templates[track])
+		actTracks = append(actTracks, track)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	// the diffs move the cursors of the tracks, restore them to the
+	// last blocks tracked since there might be blocks without logs
+	for _, track := range tracks {
+		if err := s.state.UpdateTrackCursor(track); err != nil {
+			return err
+		}
+	}
+
+	p.logger.Info("reindex done", "actions", num)
+	return nil
+}
+
+// reindexAction replays the action with the events of the tracks (one
+// track per event) and applies the diffs under the first track
+func (s *Server) reindexAction(p *providerSrv, act *sdk.Action, tracks []string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// the events might be skipped while processing, keep the tracks by log
+	track := tracks[0]
+	logTracks := map[uint64]string{}
+	for indx, evnt := range act.Events {
+		logTracks[evnt.LogIndex] = tracks[indx]
+	}
+
+	block, err := s.state.GetBlock(act.BlockNum)
+	if err != nil {
+		return err
+	}
+	act.Block = block
+
+	blockDiff := &BlockDiff{
+		Provider: p.name,
+		Track:    track,
+		Number:   act.BlockNum,
+		Hash:     act.BlockHash,
+		Block:    block,
+	}
+	if blockDiff.Diffs, err = p.process(track, act, blockDiff); err != nil {
+		return err
+	}
+	for _, letter := range blockDiff.DeadLetters {
+		if logTrack, ok := logTracks[letter.LogIndex]; ok && letter.TxHash != "" {
+			// the dead letter of an event belongs to its track
+			letter.Track = logTrack
+		}
+	}
+	for _, source := range p.provider.DataSources() {
+		blockDiff.Tracks = append(blockDiff.Tracks, trackFromDataSource(p.name, source))
+	}
+	return p.applyDiff(s.state, blockDiff)
+}
@@ -0,0 +1,29 @@
+package indexer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/eth-indexer/providers"
+	"github.com/umbracle/eth-indexer/sdk"
+)
+
+func TestReindex_Check(t *testing.T) {
+	assert.NoError(t, checkReindex("pancake"))
+
+	providers.BuiltinProviders["ticks"] = func() *sdk.Provider {
+		return &sdk.Provider{
+			BlockTrackers: []*sdk.BlockTracker{
+				{Interval: 10, Handler: func(req *sdk.HandlerReq) {}},
+			},
+		}
+	}
+	defer delete(providers.BuiltinProviders, "ticks")
+
+	err := checkReindex("ticks")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrReindexUnsupported))
+
+	assert.Error(t, checkReindex("unknown"))
+}
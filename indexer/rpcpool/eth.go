@@ -0,0 +1,85 @@
+package rpcpool
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/umbracle/go-web3"
+)
+
+// Eth is the eth namespace of the pool. It implements the
+// provider interface of the go-web3 tracker.
+type Eth struct {
+	p *Pool
+}
+
+// BlockNumber returns the number of most recent block
+func (e *Eth) BlockNumber() (uint64, error) {
+	var out string
+	if err := e.p.Call("eth_blockNumber", &out); err != nil {
+		return 0, err
+	}
+	return parseUint64orHex(out)
+}
+
+// GetBlockByNumber returns information about a block by block number
+func (e *Eth) GetBlockByNumber(i web3.BlockNumber, full bool) (*web3.Block, error) {
+	var b *web3.Block
+	if err := e.p.Call("eth_getBlockByNumber", &b, i.String(), full); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// GetBlockByHash returns information about a block by hash
+func (e *Eth) GetBlockByHash(hash web3.Hash, full bool) (*web3.Block, error) {
+	var b *web3.Block
+	if err := e.p.Call("eth_getBlockByHash", &b, hash, full); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// GetTransactionByHash returns a transaction by his hash
+func (e *Eth) GetTransactionByHash(hash web3.Hash) (*web3.Transaction, error) {
+	var txn *web3.Transaction
+	err := e.p.Call("eth_getTransactionByHash", &txn, hash)
+	return txn, err
+}
+
+// GetTransactionReceipt returns the receipt of a transaction by transaction hash
+func (e *Eth) GetTransactionReceipt(hash web3.Hash) (*web3.Receipt, error) {
+	var receipt *web3.Receipt
+	err := e.p.Call("eth_getTransactionReceipt", &receipt, hash)
+	return receipt, err
+}
+
+// GetLogs returns an array of all logs matching a given filter object
+func (e *Eth) GetLogs(filter *web3.LogFilter) ([]*web3.Log, error) {
+	var out []*web3.Log
+	if err := e.p.Call("eth_getLogs", &out, filter); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChainID returns the id of the chain
+func (e *Eth) ChainID() (*big.Int, error) {
+	var out string
+	if err := e.p.Call("eth_chainId", &out); err != nil {
+		return nil, err
+	}
+	num := new(big.Int)
+	num.SetString(strings.TrimPrefix(out, "0x"), 16)
+	return num, nil
+}
+
+func parseUint64orHex(str string) (uint64, error) {
+	base := 10
+	if strings.HasPrefix(str, "0x") {
+		str = str[2:]
+		base = 16
+	}
+	return strconv.ParseUint(str, base, 64)
+}
@@ -0,0 +1,358 @@
+package rpcpool
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/umbracle/go-web3/jsonrpc/codec"
+	"github.com/umbracle/go-web3/jsonrpc/transport"
+)
+
+// Endpoint is a json-rpc endpoint of the pool
+type Endpoint struct {
+	URL string
+
+	// Weight is the share of the requests sent to the endpoint
+	// relative to the other endpoints. It defaults to 1.
+	Weight uint64
+
+	// RPS is the maximum number of requests per second sent to the
+	// endpoint. Zero means no limit.
+	RPS float64
+}
+
+// ParseEndpoints parses a comma separated list of endpoints. Each endpoint
+// is an url optionally followed by its weight and rps limit separated by
+// semicolons (i.e. http://localhost:8545;weight=2;rps=10).
+func ParseEndpoints(str string) ([]*Endpoint, error) {
+	endpoints := []*Endpoint{}
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ";")
+		endpoint := &Endpoint{
+			URL: parts[0],
+		}
+		for _, opt := range parts[1:] {
+			spl := strings.SplitN(opt, "=", 2)
+			if len(spl) != 2 {
+				return nil, fmt.Errorf("bad endpoint option '%s'", opt)
+			}
+			var err error
+			switch spl[0] {
+			case "weight":
+				endpoint.Weight, err = strconv.ParseUint(spl[1], 10, 64)
+			case "rps":
+				endpoint.RPS, err = strconv.ParseFloat(spl[1], 64)
+			default:
+				err = fmt.Errorf("option not found")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("bad endpoint option '%s': %v", opt, err)
+			}
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+// Config is the configuration of the pool
+type Config struct {
+	// Retries is the number of times a failed request is retried
+	Retries uint64
+
+	// Backoff is the initial wait before retrying a request once
+	// all the endpoints have failed. It doubles on each retry up
+	// to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// HealthCheckInterval is the period to check the endpoints
+	HealthCheckInterval time.Duration
+}
+
+// DefaultConfig returns the default configuration of the pool
+func DefaultConfig() *Config {
+	return &Config{
+		Retries:             5,
+		Backoff:             500 * time.Millisecond,
+		MaxBackoff:          10 * time.Second,
+		HealthCheckInterval: 10 * time.Second,
+	}
+}
+
+// Pool is a json-rpc client that distributes the requests among several
+// endpoints. The failed requests are retried with another endpoint and
+// the endpoints that fail are not used until they pass a health check.
+type Pool struct {
+	logger    hclog.Logger
+	config    *Config
+	endpoints []*endpoint
+
+	lock sync.Mutex
+	rand *rand.Rand
+
+	closeCh chan struct{}
+}
+
+// NewPool creates a pool with the endpoints
+func NewPool(endpoints []*Endpoint, config *Config, logger hclog.Logger) (*Pool, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no json-rpc endpoints")
+	}
+	if config == nil {
+		config = DefaultConfig()
+	}
+	p := &Pool{
+		logger:    logger,
+		config:    config,
+		endpoints: []*endpoint{},
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		closeCh:   make(chan struct{}),
+	}
+	for _, e := range endpoints {
+		trans, err := transport.NewTransport(e.URL)
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("failed to connect with %s: %v", e.URL, err)
+		}
+		weight := e.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		p.endpoints = append(p.endpoints, &endpoint{
+			url:       e.URL,
+			weight:    weight,
+			transport: trans,
+			limiter:   newLimiter(e.RPS),
+			healthy:   true,
+		})
+	}
+	if config.HealthCheckInterval != 0 {
+		go p.runHealthCheck()
+	}
+	return p, nil
+}
+
+// Call makes a json-rpc request with one of the endpoints. If the request
+// fails it is retried with the other endpoints and then with backoff.
+// The errors returned by the node are not retried.
+func (p *Pool) Call(method string, out interface{}, params ...interface{}) error {
+	tried := map[*endpoint]struct{}{}
+	backoff := p.config.Backoff
+
+	var err error
+	for attempt := uint64(0); attempt <= p.config.Retries; attempt++ {
+		if len(tried) == len(p.endpoints) {
+			// all the endpoints failed, wait before another round
+			select {
+			case <-time.After(backoff):
+			case <-p.closeCh:
+				return err
+			}
+			if backoff *= 2; backoff > p.config.MaxBackoff {
+				backoff = p.config.MaxBackoff
+			}
+			tried = map[*endpoint]struct{}{}
+		}
+
+		e := p.pick(tried)
+		tried[e] = struct{}{}
+
+		e.limiter.wait()
+		if err = e.transport.Call(method, out, params...); err == nil {
+			return nil
+		}
+		if !isRetryable(err) {
+			return err
+		}
+		p.logger.Debug("request failed", "endpoint", e.url, "method", method, "err", err)
+		p.setHealthy(e, false, err)
+	}
+	return err
+}
+
+// Subscribe starts a subscription with one of the endpoints that support
+// subscriptions (i.e. websocket). It returns an error if there is none.
+func (p *Pool) Subscribe(method string, callback func(b []byte)) (func() error, error) {
+	var err error
+	for _, e := range p.endpoints {
+		pub, ok := e.transport.(transport.PubSubTransport)
+		if !ok || !e.isHealthy() {
+			continue
+		}
+		e.limiter.wait()
+
+		var cancel func() error
+		if cancel, err = pub.Subscribe(method, callback); err == nil {
+			return cancel, nil
+		}
+		p.logger.Debug("subscription failed", "endpoint", e.url, "method", method, "err", err)
+	}
+	if err == nil {
+		err = fmt.Errorf("no endpoint supports subscriptions")
+	}
+	return nil, err
+}
+
+// Close closes the connections with the endpoints
+func (p *Pool) Close() error {
+	select {
+	case <-p.closeCh:
+	default:
+		close(p.closeCh)
+	}
+	for _, e := range p.endpoints {
+		e.transport.Close()
+	}
+	return nil
+}
+
+// Eth returns the reference to the eth namespace
+func (p *Pool) Eth() *Eth {
+	return &Eth{p}
+}
+
+// pick selects an endpoint weighted by its weight. It prefers the healthy
+// endpoints that have not been tried yet for the request.
+func (p *Pool) pick(tried map[*endpoint]struct{}) *endpoint {
+	candidates := []*endpoint{}
+	for _, e := range p.endpoints {
+		if _, ok := tried[e]; !ok && e.isHealthy() {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		// there are no healthy endpoints left, try the others
+		// in case they have recovered
+		for _, e := range p.endpoints {
+			if _, ok := tried[e]; !ok {
+				candidates = append(candidates, e)
+			}
+		}
+	}
+
+	total := uint64(0)
+	for _, e := range candidates {
+		total += e.weight
+	}
+
+	p.lock.Lock()
+	n := uint64(p.rand.Int63n(int64(total)))
+	p.lock.Unlock()
+
+	for _, e := range candidates {
+		if n < e.weight {
+			return e
+		}
+		n -= e.weight
+	}
+	return candidates[len(candidates)-1]
+}
+
+func (p *Pool) runHealthCheck() {
+	ticker := time.NewTicker(p.config.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.closeCh:
+			return
+		}
+		for _, e := range p.endpoints {
+			e.limiter.wait()
+
+			var out string
+			err := e.transport.Call("eth_blockNumber", &out)
+			p.setHealthy(e, err == nil, err)
+		}
+	}
+}
+
+func (p *Pool) setHealthy(e *endpoint, healthy bool, err error) {
+	e.lock.Lock()
+	changed := e.healthy != healthy
+	e.healthy = healthy
+	e.lock.Unlock()
+
+	if !changed {
+		return
+	}
+	if healthy {
+		p.logger.Info("endpoint healthy", "endpoint", e.url)
+	} else {
+		p.logger.Warn("endpoint unhealthy", "endpoint", e.url, "err", err)
+	}
+}
+
+type endpoint struct {
+	url       string
+	weight    uint64
+	transport transport.Transport
+	limiter   *limiter
+
+	lock    sync.Mutex
+	healthy bool
+}
+
+func (e *endpoint) isHealthy() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.healthy
+}
+
+// isRetryable returns false for the errors returned by the node
+// unless the node asks to slow down the requests
+func isRetryable(err error) bool {
+	var obj *codec.ErrorObject
+	if !errors.As(err, &obj) {
+		// connection or decoding error
+		return true
+	}
+	if obj.Code == -32005 {
+		return true
+	}
+	msg := strings.ToLower(obj.Message)
+	return strings.Contains(msg, "rate limit") || strings.Contains(msg, "limit exceeded") || strings.Contains(msg, "too many requests")
+}
+
+// limiter spaces the requests to an endpoint to honor its rps limit
+type limiter struct {
+	lock     sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(rps float64) *limiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &limiter{
+		interval: time.Duration(float64(time.Second) / rps),
+	}
+}
+
+func (l *limiter) wait() {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.lock.Unlock()
+
+	time.Sleep(delay)
+}
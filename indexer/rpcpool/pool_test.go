@@ -0,0 +1,139 @@
+package rpcpool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+type testEndpoint struct {
+	*httptest.Server
+	calls uint64
+}
+
+func newTestEndpoint(t *testing.T, response string) *testEndpoint {
+	e := &testEndpoint{}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint64(&e.calls, 1)
+		if response == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *testEndpoint) numCalls() uint64 {
+	return atomic.LoadUint64(&e.calls)
+}
+
+func testConfig() *Config {
+	return &Config{
+		Retries:    3,
+		Backoff:    10 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+	}
+}
+
+func TestPool_Failover(t *testing.T) {
+	bad := newTestEndpoint(t, "")
+	good := newTestEndpoint(t, `{"jsonrpc":"2.0","id":1,"result":"0x10"}`)
+
+	pool, err := NewPool([]*Endpoint{
+		{URL: bad.URL, Weight: 1000},
+		{URL: good.URL, Weight: 1},
+	}, testConfig(), hclog.NewNullLogger())
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	for i := 0; i < 5; i++ {
+		num, err := pool.Eth().BlockNumber()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(16), num)
+	}
+
+	// the bad endpoint is not used after it fails
+	assert.Equal(t, uint64(1), bad.numCalls())
+	assert.Equal(t, uint64(5), good.numCalls())
+}
+
+func TestPool_Retry(t *testing.T) {
+	bad := newTestEndpoint(t, "")
+
+	pool, err := NewPool([]*Endpoint{
+		{URL: bad.URL},
+	}, testConfig(), hclog.NewNullLogger())
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.Eth().BlockNumber()
+	assert.Error(t, err)
+	assert.Equal(t, uint64(4), bad.numCalls())
+}
+
+func TestPool_NodeError(t *testing.T) {
+	e := newTestEndpoint(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`)
+
+	pool, err := NewPool([]*Endpoint{
+		{URL: e.URL},
+	}, testConfig(), hclog.NewNullLogger())
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	// the errors of the node are not retried
+	_, err = pool.Eth().BlockNumber()
+	assert.Error(t, err)
+	assert.Equal(t, uint64(1), e.numCalls())
+}
+
+func TestPool_RateLimit(t *testing.T) {
+	e := newTestEndpoint(t, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`)
+
+	pool, err := NewPool([]*Endpoint{
+		{URL: e.URL, RPS: 20},
+	}, testConfig(), hclog.NewNullLogger())
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := pool.Eth().BlockNumber()
+		assert.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(now)), int64(200*time.Millisecond))
+}
+
+func TestPool_HealthCheck(t *testing.T) {
+	e := newTestEndpoint(t, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`)
+
+	config := testConfig()
+	config.HealthCheckInterval = 10 * time.Millisecond
+
+	pool, err := NewPool([]*Endpoint{
+		{URL: e.URL},
+	}, config, hclog.NewNullLogger())
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	pool.setHealthy(pool.endpoints[0], false, nil)
+	assert.Eventually(t, pool.endpoints[0].isHealthy, time.Second, 10*time.Millisecond)
+}
+
+func TestPool_ParseEndpoints(t *testing.T) {
+	endpoints, err := ParseEndpoints("http://a, http://b;weight=2;rps=10.5")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Endpoint{
+		{URL: "http://a"},
+		{URL: "http://b", Weight: 2, RPS: 10.5},
+	}, endpoints)
+
+	_, err = ParseEndpoints("http://a;other=1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,393 @@
+package indexer
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/umbracle/eth-indexer/sdk"
+)
+
+// TableMigration is the plan to reconcile a table of a provider
+// with the table in the database
+type TableMigration struct {
+	Table string
+
+	// Version is the version of the schema after the migration
+	Version uint64
+
+	// DDL are the statements to apply (if any)
+	DDL []string
+
+	// Indexes are the statements to reconcile the secondary indexes. They
+	// are applied after the DDL since they cannot run in a transaction.
+	Indexes []string
+
+	// Warnings are the differences that the migration does not reconcile
+	Warnings []string
+
+	signature string
+	changed   bool
+}
+
+// tableCatalog are the columns of a table in the catalog of Postgresql
+type tableCatalog struct {
+	// columns is the type of each column by name
+	columns map[string]string
+
+	// ids are the columns of the unique constraint of the table
+	ids map[string]struct{}
+
+	// indexes are the indexes of the table
+	indexes []catalogIndex
+}
+
+// catalogIndex is an index of a table in the catalog of Postgresql
+type catalogIndex struct {
+	Name    string `db:"name"`
+	Comment string `db:"comment"`
+	Valid   bool   `db:"valid"`
+}
+
+// indexCommentPrefix is the prefix of the comment of the indexes created
+// from the schema. The comment includes the definition of the index so
+// that the changes in the schema are detected.
+const indexCommentPrefix = "eth-indexer: "
+
+// indexDefinition returns the columns and the condition of the index
+func indexDefinition(index *sdk.Index) string {
+	cols := []string{}
+	for _, f := range index.Fields {
+		col := f.Name
+		if f.Desc {
+			col += " DESC"
+		}
+		cols = append(cols, col)
+	}
+	def := "(" + strings.Join(cols, ", ") + ")"
+	if index.Where != "" {
+		def += " WHERE " + index.Where
+	}
+	return def
+}
+
+// planIndexes returns the statements to create the indexes of the table
+// that do not exist (or changed) and to drop the ones that are not in the
+// schema anymore. Only the indexes created from the schema are dropped.
+func planIndexes(t *sdk.Table, indexes []catalogIndex) []string {
+	existing := map[string]catalogIndex{}
+	for _, index := range indexes {
+		existing[index.Name] = index
+	}
+
+	ddl := []string{}
+	declared := map[string]struct{}{}
+	for _, index := range t.Indexes {
+		name := strings.ToLower(index.Name)
+		declared[name] = struct{}{}
+
+		def := indexDefinition(index)
+		if current, ok := existing[name]; ok {
+			if current.Valid && current.Comment == indexCommentPrefix+def {
+				continue
+			}
+			// the definition changed or the index was not built
+			ddl = append(ddl, fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", name))
+		}
+		ddl = append(ddl,
+			fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s %s", name, t.Name, def),
+			fmt.Sprintf("COMMENT ON INDEX %s IS %s", name, quoteLiteral(indexCommentPrefix+def)),
+		)
+	}
+	for _, index := range indexes {
+		if _, ok := declared[index.Name]; ok {
+			continue
+		}
+		if strings.HasPrefix(index.Comment, indexCommentPrefix) {
+			ddl = append(ddl, fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", index.Name))
+		}
+	}
+	return ddl
+}
+
+// hasIndex returns true if the field is the first column of an index of the
+// table, either the unique constraint of the IDs, a reference or a declared index
+func hasIndex(t *sdk.Table, field string) bool {
+	firstID := true
+	for _, f := range t.Fields {
+		if !strings.EqualFold(f.Name, field) {
+			firstID = firstID && !f.ID
+			continue
+		}
+		if (f.ID && firstID) || f.References != nil {
+			return true
+		}
+	}
+	for _, index := range t.Indexes {
+		if strings.EqualFold(index.Fields[0].Name, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// safeTypeChanges are the changes of the type of a column that
+// do not lose information (i.e. widening an integer)
+var safeTypeChanges = map[string][]string{
+	"bigint":        {"numeric(78,0)", "numeric"},
+	"numeric(78,0)": {"numeric"},
+}
+
+// compatibleTypes are the types of the columns that already store the
+// values of another type (i.e. the unbounded numeric columns created
+// before the integers had a precision), the columns are not changed
+var compatibleTypes = map[string][]string{
+	"numeric": {"numeric(78,0)"},
+}
+
+func isSafeTypeChange(from, to string) bool {
+	return hasTypeChange(safeTypeChanges, from, to)
+}
+
+func isCompatibleType(from, to string) bool {
+	return hasTypeChange(compatibleTypes, from, to)
+}
+
+func hasTypeChange(changes map[string][]string, from, to string) bool {
+	for _, typ := range changes[from] {
+		if typ == to {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaSignature describes the fields of the table, a new version
+// of the schema is recorded whenever it changes
+func schemaSignature(t *sdk.Table) string {
+	fields := []string{}
+	for _, f := range t.Fields {
+		field := strings.ToLower(f.Name) + " " + f.Type.String()
+		if f.ID {
+			field += " id"
+		}
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ", ")
+}
+
+// planMigration compares the schema of the table with the columns in the
+// catalog (nil if the table does not exist). The new columns are added with
+// their default values, the integer columns are widened and the columns with
+// a conversion are converted but the changes that might lose data (the ID
+// fields or the other type changes) are refused.
+// The secondary indexes are reconciled as well.
+func planMigration(t *sdk.Table, catalog *tableCatalog, version uint64, signature string) (*TableMigration, error) {
+	m := &TableMigration{
+		Table:     t.Name,
+		Version:   version,
+		signature: schemaSignature(t),
+	}
+	if m.signature != signature {
+		m.changed = true
+		m.Version++
+	}
+
+	if catalog == nil {
+		m.DDL = append(m.DDL, buildDDL(t))
+		m.Indexes = planIndexes(t, nil)
+		return m, nil
+	}
+	m.Indexes = planIndexes(t, catalog.indexes)
+
+	problems := []string{}
+	fields := map[string]struct{}{}
+	for _, f := range t.Fields {
+		name := strings.ToLower(f.Name)
+		fields[name] = struct{}{}
+
+		ddlType, catalogType := columnType(f)
+		typ, ok := catalog.columns[name]
+		if !ok {
+			if f.ID {
+				problems = append(problems, fmt.Sprintf("ID field '%s' is new", f.Name))
+				continue
+			}
+			ddl := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", t.Name, f.Name, ddlType)
+			if f.Default != nil {
+				val, err := f.Encode(f.Default)
+				if err != nil {
+					return nil, fmt.Errorf("table '%s': incorrect default of field '%s': %v", t.Name, f.Name, err)
+				}
+				ddl += " DEFAULT " + quoteLiteral(val)
+			}
+			m.DDL = append(m.DDL, ddl)
+			continue
+		}
+
+		if _, isID := catalog.ids[name]; isID != f.ID {
+			if f.ID {
+				problems = append(problems, fmt.Sprintf("field '%s' is now an ID field", f.Name))
+			} else {
+				problems = append(problems, fmt.Sprintf("field '%s' is not an ID field anymore", f.Name))
+			}
+		}
+		if typ == catalogType || isCompatibleType(typ, catalogType) {
+			continue
+		}
+		if f.Convert != nil && f.Convert.From == typ {
+			m.Warnings = append(m.Warnings, fmt.Sprintf("field '%s' converts the type from %s to %s", f.Name, typ, catalogType))
+			m.DDL = append(m.DDL, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s", t.Name, f.Name, ddlType, f.Convert.Using))
+			continue
+		}
+		if !isSafeTypeChange(typ, catalogType) {
+			problems = append(problems, fmt.Sprintf("field '%s' cannot change the type from %s to %s", f.Name, typ, catalogType))
+			continue
+		}
+		m.Warnings = append(m.Warnings, fmt.Sprintf("field '%s' changes the type from %s to %s", f.Name, typ, catalogType))
+		m.DDL = append(m.DDL, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s", t.Name, f.Name, ddlType))
+	}
+
+	// the columns without a field are kept so that no data is lost
+	for _, name := range sortedColumns(catalog.columns) {
+		if _, ok := fields[name]; ok {
+			continue
+		}
+		if _, ok := catalog.ids[name]; ok {
+			problems = append(problems, fmt.Sprintf("ID field '%s' was removed", name))
+		} else {
+			m.Warnings = append(m.Warnings, fmt.Sprintf("column '%s' is not in the schema and it is not dropped", name))
+		}
+	}
+
+	if len(problems) != 0 {
+		return nil, fmt.Errorf("table '%s' cannot be migrated: %s", t.Name, strings.Join(problems, ", "))
+	}
+	return m, nil
+}
+
+func sortedColumns(columns map[string]string) []string {
+	names := []string{}
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// quoteLiteral quotes a string as a constant in SQL
+func quoteLiteral(val string) string {
+	return "'" + strings.Replace(val, "'", "''", -1) + "'"
+}
+
+// PlanTable returns the migration that reconciles the table with the schema
+func (s *State) PlanTable(t *sdk.Table) (*TableMigration, error) {
+	catalog, err := s.getTableCatalog(t.Name)
+	if err != nil {
+		return nil, err
+	}
+	version, signature, err := s.getSchemaVersion(t.Name)
+	if err != nil {
+		return nil, err
+	}
+	return planMigration(t, catalog, version, signature)
+}
+
+// MigrateTable applies the migration and records the version of the schema
+func (s *State) MigrateTable(m *TableMigration) error {
+	txn, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	for _, ddl := range m.DDL {
+		if _, err := txn.Exec(ddl); err != nil {
+			return err
+		}
+	}
+	if m.changed {
+		if _, err := txn.Exec("INSERT INTO schema_versions (tbl, version, signature) VALUES ($1, $2, $3) ON CONFLICT (tbl) DO UPDATE SET version = $2, signature = $3", m.Table, m.Version, m.signature); err != nil {
+			return err
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		return err
+	}
+
+	// the indexes are built concurrently so that the
+	// writes in the table are not blocked meanwhile
+	for _, ddl := range m.Indexes {
+		if _, err := s.db.Exec(ddl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSchemaVersion returns the version of the schema of the table
+func (s *State) GetSchemaVersion(table string) (uint64, error) {
+	version, _, err := s.getSchemaVersion(table)
+	return version, err
+}
+
+func (s *State) getSchemaVersion(table string) (uint64, string, error) {
+	var obj struct {
+		Version   uint64 `db:"version"`
+		Signature string `db:"signature"`
+	}
+	if err := s.db.Get(&obj, "SELECT version, signature FROM schema_versions WHERE tbl = $1", table); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, "", nil
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "42P01" {
+			// the table does not exist yet in a dry run
+			return 0, "", nil
+		}
+		return 0, "", err
+	}
+	return obj.Version, obj.Signature, nil
+}
+
+// getTableCatalog returns the columns of the table or nil if it does not exist
+func (s *State) getTableCatalog(table string) (*tableCatalog, error) {
+	var columns []struct {
+		Name string `db:"name"`
+		Type string `db:"type"`
+	}
+	query := "SELECT attname AS name, format_type(atttypid, atttypmod) AS type FROM pg_attribute WHERE attrelid = to_regclass($1) AND attnum > 0 AND NOT attisdropped"
+	if err := s.db.Select(&columns, query, table); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	var ids []string
+	query = "SELECT a.attname FROM pg_constraint c JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = ANY(c.conkey) WHERE c.conrelid = to_regclass($1) AND c.contype = 'u'"
+	if err := s.db.Select(&ids, query, table); err != nil {
+		return nil, err
+	}
+
+	var indexes []catalogIndex
+	query = "SELECT c.relname AS name, COALESCE(obj_description(c.oid, 'pg_class'), '') AS comment, i.indisvalid AS valid FROM pg_index i JOIN pg_class c ON c.oid = i.indexrelid WHERE i.indrelid = to_regclass($1) ORDER BY c.relname"
+	if err := s.db.Select(&indexes, query, table); err != nil {
+		return nil, err
+	}
+
+	catalog := &tableCatalog{
+		indexes: indexes,
+		columns: map[string]string{},
+		ids:     map[string]struct{}{},
+	}
+	for _, col := range columns {
+		catalog.columns[col.Name] = col.Type
+	}
+	for _, id := range ids {
+		catalog.ids[id] = struct{}{}
+	}
+	return catalog, nil
+}
@@ -0,0 +1,186 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/eth-indexer/sdk"
+)
+
+func TestSchemaMigration_Plan(t *testing.T) {
+	tb := &sdk.Table{
+		Name: "token",
+		Fields: []*sdk.Field{
+			{Name: "address", Type: sdk.TypeAddress, ID: true},
+			{Name: "numPairs", Type: sdk.TypeUint},
+			{Name: "name", Type: sdk.TypeString, Default: "it's"},
+			{Name: "flag", Type: sdk.TypeBool},
+		},
+	}
+
+	// the table does not exist
+	m, err := planMigration(tb, nil, 0, "")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), m.Version)
+	assert.Equal(t, []string{buildDDL(tb)}, m.DDL)
+
+	catalog := &tableCatalog{
+		columns: map[string]string{
+			"address":  "text",
+			"numpairs": "bigint",
+			"old":      "text",
+		},
+		ids: map[string]struct{}{"address": {}},
+	}
+	m, err = planMigration(tb, catalog, 1, "address address id, numpairs int")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), m.Version)
+	assert.Equal(t, []string{
+		"ALTER TABLE token ALTER COLUMN numPairs TYPE numeric(78,0)",
+		"ALTER TABLE token ADD COLUMN name text DEFAULT 'it''s'",
+		"ALTER TABLE token ADD COLUMN flag boolean",
+	}, m.DDL)
+	assert.Equal(t, []string{
+		"field 'numPairs' changes the type from bigint to numeric(78,0)",
+		"column 'old' is not in the schema and it is not dropped",
+	}, m.Warnings)
+
+	// the same schema keeps the version
+	m, err = planMigration(tb, catalog, 2, schemaSignature(tb))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), m.Version)
+	assert.False(t, m.changed)
+
+	// the numeric columns created before the integers had a precision are kept
+	catalog.columns["numpairs"] = "numeric"
+	catalog.columns["name"] = "text"
+	catalog.columns["flag"] = "boolean"
+	m, err = planMigration(tb, catalog, 2, schemaSignature(tb))
+	assert.NoError(t, err)
+	assert.Empty(t, m.DDL)
+}
+
+func TestSchemaMigration_Convert(t *testing.T) {
+	split := sdk.BlockSplit(100)
+	tb := &sdk.Table{
+		Name: "token_snapshot",
+		Fields: []*sdk.Field{
+			{Name: "address", Type: sdk.TypeAddress, ID: true},
+			{Name: split.Column, Type: split.Type, ID: true, Convert: split.Convert},
+		},
+	}
+
+	// the snapshot table of a previous version with the windows in text
+	catalog := &tableCatalog{
+		columns: map[string]string{
+			"address": "text",
+			"block":   "text",
+		},
+		ids: map[string]struct{}{"address": {}, "block": {}},
+	}
+	m, err := planMigration(tb, catalog, 1, "address address id, block address id")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE token_snapshot ALTER COLUMN block TYPE numeric(78,0) USING block::numeric * 100",
+	}, m.DDL)
+
+	// only the type of the conversion is converted
+	catalog.columns["block"] = "bytea"
+	_, err = planMigration(tb, catalog, 1, "")
+	assert.EqualError(t, err, "table 'token_snapshot' cannot be migrated: field 'block' cannot change the type from bytea to numeric(78,0)")
+}
+
+func TestSchemaMigration_Refuse(t *testing.T) {
+	tb := &sdk.Table{
+		Name: "token",
+		Fields: []*sdk.Field{
+			{Name: "id", Type: sdk.TypeString, ID: true},
+			{Name: "amount", Type: sdk.TypeInt},
+		},
+	}
+	catalog := &tableCatalog{
+		columns: map[string]string{
+			"address": "text",
+			"id":      "text",
+			"amount":  "numeric(78,0)",
+		},
+		ids: map[string]struct{}{"address": {}},
+	}
+	_, err := planMigration(tb, catalog, 1, "")
+	assert.EqualError(t, err, "table 'token' cannot be migrated: field 'id' is now an ID field, field 'amount' cannot change the type from numeric(78,0) to bigint, ID field 'address' was removed")
+}
+
+func TestSchemaMigration_Indexes(t *testing.T) {
+	tb := &sdk.Table{
+		Name: "swap",
+		Fields: []*sdk.Field{
+			{Name: "id", Type: sdk.TypeString, ID: true},
+			{Name: "pair", Type: sdk.TypeAddress},
+			{Name: "timestamp", Type: sdk.TypeTimestamp},
+			{Name: "amount", Type: sdk.TypeUint},
+		},
+		Indexes: []*sdk.Index{
+			{Name: "swap_timestamp_idx", Fields: []sdk.IndexField{sdk.Desc("timestamp")}},
+			{Name: "swap_pair_timestamp_idx", Fields: []sdk.IndexField{sdk.Asc("pair"), sdk.Desc("timestamp")}, Where: "amount > 0"},
+		},
+	}
+
+	// create all the indexes
+	ddl := planIndexes(tb, nil)
+	assert.Equal(t, []string{
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS swap_timestamp_idx ON swap (timestamp DESC)",
+		"COMMENT ON INDEX swap_timestamp_idx IS 'eth-indexer: (timestamp DESC)'",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS swap_pair_timestamp_idx ON swap (pair, timestamp DESC) WHERE amount > 0",
+		"COMMENT ON INDEX swap_pair_timestamp_idx IS 'eth-indexer: (pair, timestamp DESC) WHERE amount > 0'",
+	}, ddl)
+
+	existing := []catalogIndex{
+		{Name: "swap_id_key", Valid: true},
+		{Name: "swap_timestamp_idx", Comment: "eth-indexer: (timestamp)", Valid: true},
+		{Name: "swap_pair_timestamp_idx", Comment: "eth-indexer: (pair, timestamp DESC) WHERE amount > 0", Valid: true},
+		{Name: "swap_amount_idx", Comment: "eth-indexer: (amount)", Valid: true},
+		{Name: "manual_idx", Valid: true},
+	}
+
+	// the changed index is built again and the removed one dropped, the
+	// indexes that are not created from the schema are kept
+	ddl = planIndexes(tb, existing)
+	assert.Equal(t, []string{
+		"DROP INDEX CONCURRENTLY IF EXISTS swap_timestamp_idx",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS swap_timestamp_idx ON swap (timestamp DESC)",
+		"COMMENT ON INDEX swap_timestamp_idx IS 'eth-indexer: (timestamp DESC)'",
+		"DROP INDEX CONCURRENTLY IF EXISTS swap_amount_idx",
+	}, ddl)
+
+	// an index that failed to build is built again
+	existing = []catalogIndex{
+		{Name: "swap_timestamp_idx", Comment: "eth-indexer: (timestamp DESC)", Valid: false},
+		{Name: "swap_pair_timestamp_idx", Comment: "eth-indexer: (pair, timestamp DESC) WHERE amount > 0", Valid: true},
+	}
+	ddl = planIndexes(tb, existing)
+	assert.Len(t, ddl, 3)
+	assert.Equal(t, "DROP INDEX CONCURRENTLY IF EXISTS swap_timestamp_idx", ddl[0])
+}
+
+func TestSchemaMigration_HasIndex(t *testing.T) {
+	tb := &sdk.Table{
+		Name: "swap",
+		Fields: []*sdk.Field{
+			{Name: "id", Type: sdk.TypeString, ID: true},
+			{Name: "index", Type: sdk.TypeInt, ID: true},
+			{Name: "pair", Type: sdk.TypeAddress, References: &sdk.Reference{Table: "pair"}},
+			{Name: "timestamp", Type: sdk.TypeTimestamp},
+			{Name: "amount", Type: sdk.TypeUint},
+		},
+		Indexes: []*sdk.Index{
+			{Fields: []sdk.IndexField{sdk.Desc("timestamp"), sdk.Asc("amount")}},
+		},
+	}
+	assert.True(t, hasIndex(tb, "id"))
+	assert.True(t, hasIndex(tb, "pair"))
+	assert.True(t, hasIndex(tb, "timestamp"))
+
+	// not the first column of the index
+	assert.False(t, hasIndex(tb, "index"))
+	assert.False(t, hasIndex(tb, "amount"))
+}
@@ -1,13 +1,17 @@
 package indexer
 
 import (
+	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/eth-indexer/indexer/rpcpool"
 	"github.com/umbracle/eth-indexer/providers"
 	"github.com/umbracle/eth-indexer/sdk"
-	"github.com/umbracle/go-web3/jsonrpc"
+	"github.com/umbracle/go-web3"
 )
 
 type Config struct {
@@ -15,7 +19,36 @@ type Config struct {
 	JSONRPCEndpoint string
 	Database        string
 	BatchSize       uint64
-	Provider        string
+	Providers       []string
+
+	// Endpoints are the json-rpc endpoints of the pool, they are
+	// used along with the JSONRPCEndpoint if both are set
+	Endpoints []*rpcpool.Endpoint
+
+	// Pool is the configuration of the pool of endpoints
+	Pool *rpcpool.Config
+
+	// MaxReorgDepth is the deepest reorg that is reverted, the diffs
+	// are journaled for that number of blocks (optional)
+	MaxReorgDepth uint64
+
+	// HeadPollInterval is the period to poll the head of the chain
+	// if the endpoints do not support the newHeads subscription
+	HeadPollInterval time.Duration
+
+	// HTTPAddr is the address to serve the metrics and the status (optional)
+	HTTPAddr string
+
+	// FailurePolicy overrides the failure policy of the providers
+	FailurePolicy map[string]sdk.FailurePolicy
+
+	// Reindex drops the tables of the providers and replays the
+	// handlers with the archived logs instead of tracking the chain
+	Reindex bool
+
+	// DryRun plans the migrations of the tables of the providers
+	// without applying them or tracking the chain
+	DryRun bool
 }
 
 type Server struct {
@@ -26,18 +59,61 @@ type Server struct {
 	// grpcServer *grpc.Server
 	tracker *trackerSrv
 	state   *State
+	client  *rpcpool.Pool
+
+	schemas   map[string]*sdk.Table
+	providers map[string]*providerSrv
+
+	// haltCh receives the error of a provider that halts the process
+	haltCh chan error
+
+	// migrations are the migrations planned in a dry run
+	migrations []*TableMigration
 
-	schemas map[string]*sdk.Table
+	// unindexedLock guards the orderBy fields without an index
+	// that have been already reported
+	unindexedLock sync.Mutex
+	unindexed     map[string]struct{}
 }
 
 func NewServer(config *Config, logger hclog.Logger) (*Server, error) {
+	if config.DryRun && config.Reindex {
+		return nil, fmt.Errorf("the dry run is not supported while reindexing")
+	}
+
 	srv := &Server{
-		config:  config,
-		logger:  logger,
-		schemas: map[string]*sdk.Table{},
+		config:    config,
+		logger:    logger,
+		schemas:   map[string]*sdk.Table{},
+		providers: map[string]*providerSrv{},
+		haltCh:    make(chan error, 1),
+		unindexed: map[string]struct{}{},
+	}
+
+	endpoints := append([]*rpcpool.Endpoint{}, config.Endpoints...)
+	if config.JSONRPCEndpoint != "" {
+		endpoints = append(endpoints, &rpcpool.Endpoint{URL: config.JSONRPCEndpoint})
+	}
+
+	// the pool of json-rpc endpoints is shared by the tracker and the providers.
+	// The endpoints are optional when reindexing since the logs are archived
+	// and in a dry run.
+	if len(endpoints) != 0 || !(config.Reindex || config.DryRun) {
+		client, err := rpcpool.NewPool(endpoints, config.Pool, logger.Named("rpcpool"))
+		if err != nil {
+			return nil, err
+		}
+		srv.client = client
 	}
 
-	state, err := newState(config.Database)
+	var state *State
+	var err error
+	if config.DryRun {
+		// the dry run does not write in the database, not even the migrations of the state
+		state, err = openState(config.Database)
+	} else {
+		state, err = newState(config.Database)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -47,51 +123,158 @@ func NewServer(config *Config, logger hclog.Logger) (*Server, error) {
 	// srv.addSchema()
 
 	srv.tracker = &trackerSrv{
-		logger: logger.Named("tracker"),
-		srv:    srv,
+		logger:   logger.Named("tracker"),
+		srv:      srv,
+		tracks:   map[string]struct{}{},
+		progress: map[string]*trackProgress{},
 	}
 
 	// srv.addIndexers()
 
-	indexer, err := srv.setupIndexer()
-	if err != nil {
-		return nil, err
+	if config.Reindex {
+		// refuse the reindex before the tables of any provider are dropped
+		for _, name := range config.Providers {
+			if err := checkReindex(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, name := range config.Providers {
+		if err := srv.setupProvider(name); err != nil {
+			return nil, err
+		}
+	}
+	if config.DryRun {
+		return srv, nil
 	}
-	if err := srv.tracker.setupTracker(indexer); err != nil {
+	if config.Reindex {
+		for _, name := range config.Providers {
+			if err := srv.reindex(srv.providers[name]); err != nil {
+				return nil, err
+			}
+		}
+		return srv, nil
+	}
+
+	if config.HTTPAddr != "" {
+		srv.serveHTTP(config.HTTPAddr)
+	}
+	if err := srv.tracker.setupTracker(); err != nil {
 		return nil, err
 	}
+	for _, name := range config.Providers {
+		if err := srv.tracker.startProvider(srv.providers[name]); err != nil {
+			return nil, err
+		}
+	}
 	return srv, nil
 }
 
-func (s *Server) setupIndexer() (*sdk.Provider, error) {
-	provider, err := jsonrpc.NewClient(s.config.JSONRPCEndpoint)
-	if err != nil {
-		return nil, err
+// Migrations returns the migrations of the tables planned in a dry run
+func (s *Server) Migrations() []*TableMigration {
+	return s.migrations
+}
+
+func (s *Server) setupProvider(name string) error {
+	if _, ok := s.providers[name]; ok {
+		return fmt.Errorf("provider '%s' is duplicated", name)
+	}
+	factory, ok := providers.BuiltinProviders[name]
+	if !ok {
+		return fmt.Errorf("provider '%s' not found", name)
 	}
 
-	indexer := providers.BuiltinProviders[s.config.Provider]()
+	indexer := factory()
 	if err := indexer.Init(); err != nil {
-		return nil, err
+		return fmt.Errorf("failed to init provider '%s': %v", name, err)
+	}
+	if s.client != nil {
+		// the client is not set when reindexing without endpoints
+		indexer.SetClient(s.client)
 	}
-	indexer.SetClient(provider)
 	indexer.SetStateResolver(s)
 
+	// the tables of all the providers share the same namespace
 	sss := indexer.GetSchemas()
 	for _, sch := range sss.Schemas {
+		if _, ok := s.schemas[sch.Name]; ok {
+			return fmt.Errorf("table '%s' of provider '%s' already exists", sch.Name, name)
+		}
 		s.schemas[sch.Name] = sch
 	}
 
 	// write the tables
 	for _, sch := range indexer.GetSchemas().Schemas {
-		if err := s.state.UpsertTable(sch); err != nil {
-			return nil, err
+		if s.config.Reindex {
+			if err := s.state.DropTable(sch.Name); err != nil {
+				return err
+			}
+		}
+		m, err := s.state.PlanTable(sch)
+		if err != nil {
+			return err
+		}
+		for _, warning := range m.Warnings {
+			s.logger.Warn("schema migration", "table", sch.Name, "warning", warning)
+		}
+		if s.config.DryRun {
+			s.migrations = append(s.migrations, m)
+			continue
+		}
+		if err := s.state.MigrateTable(m); err != nil {
+			return fmt.Errorf("failed to migrate table '%s': %v", sch.Name, err)
+		}
+		if len(m.DDL) != 0 || len(m.Indexes) != 0 {
+			s.logger.Info("table migrated", "table", sch.Name, "version", m.Version)
 		}
 	}
-	return indexer, nil
+	if s.config.DryRun {
+		return nil
+	}
+	for _, sch := range indexer.GetSchemas().Schemas {
+		if err := s.state.UpsertReferences(sch); err != nil {
+			return err
+		}
+	}
+
+	policy, err := resolveFailurePolicy(name, indexer, s.config)
+	if err != nil {
+		return fmt.Errorf("provider '%s': %v", name, err)
+	}
+
+	p := &providerSrv{
+		name:     name,
+		logger:   s.logger.Named(name),
+		provider: indexer,
+		policy:   policy,
+		halt:     s.halt,
+	}
+	if s.config.Reindex {
+		// the dead letters and the journal are recorded again while reindexing,
+		// the entries before refer to the objects of the dropped tables
+		if err := s.state.DeleteDeadLetters(name); err != nil {
+			return err
+		}
+		if err := s.state.DeleteJournal(name); err != nil {
+			return err
+		}
+		// the filters of the tracks resume from their cursors
+		if err := s.state.DeleteTrackerLogs(name); err != nil {
+			return err
+		}
+	}
+	if err := p.loadQuarantined(s.state); err != nil {
+		return err
+	}
+	s.providers[name] = p
+	return nil
 }
 
 func (s *Server) Stop() {
 	// TODO
+	if s.client != nil {
+		s.client.Close()
+	}
 }
 
 func (s *Server) GetObj2(table string, keys map[string]string) (*sdk.Obj, error) {
@@ -105,6 +288,62 @@ func (s *Server) GetObj2(table string, keys map[string]string) (*sdk.Obj, error)
 	return &sdk.Obj{Data: raw.Data}, nil
 }
 
+// GetTransaction implements the sdk.TransactionResolver interface
+func (s *Server) GetTransaction(hash web3.Hash) (*web3.Transaction, error) {
+	tx, err := s.state.GetTransaction(hash.String())
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, nil
+	}
+	return tx.ToTxn()
+}
+
+// checkOrderIndex warns (once) if the objects are sorted by a field without an
+// index since the query sorts the whole table
+func (s *Server) checkOrderIndex(table, field string) {
+	sch, ok := s.schemas[table]
+	if !ok || hasIndex(sch, field) {
+		return
+	}
+
+	s.unindexedLock.Lock()
+	defer s.unindexedLock.Unlock()
+
+	key := table + "." + field
+	if _, ok := s.unindexed[key]; ok {
+		return
+	}
+	s.unindexed[key] = struct{}{}
+	s.logger.Warn("objects ordered by a field without an index", "table", table, "field", field)
+}
+
 func (s *Server) GetObjs2(q *sdk.Query) ([]*sdk.Obj, error) {
-	return nil, nil
+	query := &Query{
+		Table:   q.Table,
+		First:   q.First,
+		Skip:    q.Skip,
+		OrderBy: q.OrderBy,
+		Order:   q.Order,
+	}
+	for _, cond := range q.Where {
+		query.Where = append(query.Where, QueryWhere{
+			Key:   cond.Key,
+			Val:   cond.Val,
+			Where: WhereCond(cond.Where),
+		})
+	}
+	if q.OrderBy != "" {
+		s.checkOrderIndex(q.Table, q.OrderBy)
+	}
+	raws, err := s.state.GetObjs(query)
+	if err != nil {
+		return nil, err
+	}
+	res := []*sdk.Obj{}
+	for _, raw := range raws {
+		res = append(res, &sdk.Obj{Data: raw.Data})
+	}
+	return res, nil
 }
@@ -2,14 +2,20 @@ package indexer
 
 import (
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/eth-indexer/indexer/tracker"
 	"github.com/umbracle/eth-indexer/sdk"
 	protosdk "github.com/umbracle/eth-indexer/sdk/proto"
 	"github.com/umbracle/go-web3"
@@ -18,35 +24,65 @@ import (
 type State struct {
 	db *sqlx.DB
 	i  *Server
+
+	// journalDepth is the number of blocks for which the undo journal is kept.
+	// It is the MaxBlockBacklog of the tracker since that is the deepest reorg
+	// the tracker is able to reconcile.
+	journalDepth uint64
 }
 
 func newState(path string) (*State, error) {
+	s, err := openState(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.migrate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// openState opens the state without applying the migrations
+func openState(path string) (*State, error) {
 	db, err := sqlx.Open("postgres", path)
 	if err != nil {
 		return nil, err
 	}
-	return newStateWithDB(db)
+	return openStateWithDB(db), nil
 }
 
 func newStateWithDB(db *sqlx.DB) (*State, error) {
-	s := &State{
-		db: db,
-	}
+	s := openStateWithDB(db)
 	if err := s.migrate(); err != nil {
 		return nil, err
 	}
 	return s, nil
 }
 
+func openStateWithDB(db *sqlx.DB) *State {
+	return &State{
+		db:           db,
+		journalDepth: tracker.DefaultConfig().MaxBlockBacklog,
+	}
+}
+
 func (s *State) migrate() error {
-	for _, migration := range AssetNames() {
+	for _, migration := range migrationNames() {
 		if _, err := s.db.Exec(string(MustAsset(migration))); err != nil {
-			return err
+			return fmt.Errorf("failed to apply migration '%s': %v", migration, err)
 		}
 	}
 	return nil
 }
 
+// migrationNames returns the migrations in the order of their
+// prefix since the later ones alter the tables of the previous
+func migrationNames() []string {
+	names := AssetNames()
+	sort.Strings(names)
+	return names
+}
+
 func (s *State) GetTrackByName(name string) (*proto.Track, error) {
 	var track proto.Track
 	if err := s.db.Get(&track, "SELECT * FROM tracks WHERE name = $1", name); err != nil {
@@ -55,20 +91,163 @@ func (s *State) GetTrackByName(name string) (*proto.Track, error) {
 	return &track, nil
 }
 
-func (s *State) GetTracks() ([]proto.Track, error) {
-	var tracks []proto.Track
-	if err := s.db.Select(&tracks, "SELECT * FROM tracks"); err != nil {
+// GetBlock returns the header of the block 'num'
+func (s *State) GetBlock(num uint64) (*sdk.Block, error) {
+	var obj struct {
+		Number     uint64 `db:"number"`
+		Hash       string `db:"hash"`
+		ParentHash string `db:"parent_hash"`
+		Timestamp  uint64 `db:"timestamp"`
+	}
+	if err := s.db.Get(&obj, "SELECT * FROM blocks WHERE number = $1", num); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	block := &sdk.Block{
+		Number:    obj.Number,
+		Timestamp: obj.Timestamp,
+	}
+	if err := block.Hash.UnmarshalText([]byte(obj.Hash)); err != nil {
+		return nil, err
+	}
+	if err := block.ParentHash.UnmarshalText([]byte(obj.ParentHash)); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// GetTransaction returns the stored transaction with the given hash
+func (s *State) GetTransaction(hash string) (*proto.Transaction, error) {
+	var tx proto.Transaction
+	if err := s.db.Get(&tx, "SELECT * FROM transactions WHERE hash = $1", hash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// GetTracks returns the tracks of the provider
+func (s *State) GetTracks(provider string) ([]*proto.Track, error) {
+	var tracks []*proto.Track
+	if err := s.db.Select(&tracks, "SELECT * FROM tracks WHERE provider = $1", provider); err != nil {
 		return nil, err
 	}
 	return tracks, nil
 }
 
+// archivedEvent is a log stored in the events table
+type archivedEvent struct {
+	Track string `db:"track"`
+	*proto.Event
+}
+
+const upsertEventQuery = "INSERT INTO events (track, log_index, tx_index, tx_hash, block_num, block_hash, address, topicid, topics, data, event, removed) VALUES (:track, :log_index, :tx_index, :tx_hash, :block_num, :block_hash, :address, :topicid, :topics, :data, :event, :removed) ON CONFLICT (track, block_num, log_index) DO UPDATE SET block_hash = :block_hash, tx_hash = :tx_hash, tx_index = :tx_index, address = :address, topicid = :topicid, topics = :topics, data = :data"
+
+// IterateEvents iterates over the archived logs of the tracks sorted by block and log index
+func (s *State) IterateEvents(tracks []string, handler func(track string, evnt *proto.Event) error) error {
+	query, args, err := sqlx.In("SELECT * FROM events WHERE track IN (?) ORDER BY block_num, log_index, track", tracks)
+	if err != nil {
+		return err
+	}
+	rows, err := s.db.Queryx(s.db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		obj := &archivedEvent{}
+		if err := rows.StructScan(obj); err != nil {
+			return err
+		}
+		if err := handler(obj.Track, obj.Event); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+const insertDeadLetterQuery = "INSERT INTO dead_letters (provider, track, block_num, tx_hash, log_index, tracker, address, error_type, error_msg, quarantined) VALUES (:provider, :track, :block_num, :tx_hash, :log_index, :tracker, :address, :error_type, :error_msg, :quarantined)"
+
+// GetDeadLetters returns the events (or calls) skipped by the provider
+func (s *State) GetDeadLetters(provider string) ([]*proto.DeadLetter, error) {
+	var letters []*proto.DeadLetter
+	if err := s.db.Select(&letters, "SELECT * FROM dead_letters WHERE provider = $1 ORDER BY block_num, log_index", provider); err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+// GetQuarantined returns the contracts quarantined by the provider
+func (s *State) GetQuarantined(provider string) ([]web3.Address, error) {
+	var addrs []string
+	if err := s.db.Select(&addrs, "SELECT DISTINCT address FROM dead_letters WHERE provider = $1 AND quarantined", provider); err != nil {
+		return nil, err
+	}
+	res := []web3.Address{}
+	for _, addr := range addrs {
+		res = append(res, web3.HexToAddress(addr))
+	}
+	return res, nil
+}
+
+// DeleteDeadLetters removes the dead letters of the provider
+func (s *State) DeleteDeadLetters(provider string) error {
+	if _, err := s.db.Exec("DELETE FROM dead_letters WHERE provider = $1", provider); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteJournal removes the entries of the provider in the journal
+func (s *State) DeleteJournal(provider string) error {
+	if _, err := s.db.Exec("DELETE FROM journal WHERE provider = $1", provider); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteTrackerLogs removes the logs and the last block stored by the
+// tracker for the filters of the tracks of the provider
+func (s *State) DeleteTrackerLogs(provider string) error {
+	txn, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	if _, err := txn.Exec("DELETE FROM tracker_logs WHERE entry IN (SELECT name FROM tracks WHERE provider = $1)", provider); err != nil {
+		return err
+	}
+	if _, err := txn.Exec("DELETE FROM tracker_kv WHERE key IN (SELECT 'lastBlock_' || name FROM tracks WHERE provider = $1)", provider); err != nil {
+		return err
+	}
+	return txn.Commit()
+}
+
+// DropTable removes the table
+func (s *State) DropTable(name string) error {
+	// drop as well the foreign keys of the tables that reference it
+	if _, err := s.db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", name)); err != nil {
+		return err
+	}
+	return nil
+}
+
+const upsertTransactionQuery = "INSERT INTO transactions (hash, nonce, block_hash, block_num, tx_index, from_addr, to_addr, value, gas, gas_price, input) VALUES (:hash, :nonce, :block_hash, :block_num, :tx_index, :from_addr, :to_addr, :value, :gas, :gas_price, :input) ON CONFLICT (hash) DO UPDATE SET block_hash = :block_hash, block_num = :block_num, tx_index = :tx_index"
+
+const upsertTrackQuery = "INSERT INTO tracks (name, to_addr, from_addr, topic, startblock, lastblocknum, lastblockhash, synced, template, provider, calls, blocks) VALUES (:name, :to_addr, :from_addr, :topic, :startblock, 0, '', false, :template, :provider, :calls, :blocks) ON CONFLICT DO NOTHING"
+
 func (s *State) UpsertTrack(t *proto.Track) error {
 	// safe check
 	if _, err := filterConfigFromTracker(t); err != nil {
 		return err
 	}
-	_, err := s.db.NamedExec("INSERT INTO tracks (name, to_addr, from_addr, topic, startblock, lastblocknum, lastblockhash, synced) VALUES (:name, :to_addr, :from_addr, :topic, :startblock, 0, '', false) ON CONFLICT DO NOTHING", t)
+	_, err := s.db.NamedExec(upsertTrackQuery, t)
 	if err != nil {
 		return err
 	}
@@ -82,6 +261,14 @@ func (s *State) UpdateTrackSyncBlock(name string, blockNum uint64, blockHash web
 	return nil
 }
 
+// UpdateTrackCursor sets the cursor of the track
+func (s *State) UpdateTrackCursor(t *proto.Track) error {
+	if _, err := s.db.Exec("UPDATE tracks SET lastblockhash = $1, lastblocknum = $2 WHERE name = $3", t.LastBlockHash, t.LastBlockNum, t.Name); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s *State) UpdateTrackSynced(name string, synced bool) error {
 	if _, err := s.db.Exec("UPDATE tracks SET synced = $1 WHERE name = $2", synced, name); err != nil {
 		return err
@@ -127,20 +314,38 @@ func (s *State) GetObjs(q *Query) ([]*ResObj, error) {
 
 	res := []*ResObj{}
 
-	query := "SELECT * FROM " + q.Table
-	if q.First != 0 {
-		query += " LIMIT " + strconv.Itoa(int(q.First))
+	query := "SELECT " + selectColumns(sch) + " FROM " + q.Table
+	where := []string{}
+	args := []interface{}{}
+	for _, cond := range q.Where {
+		if cond.Where != WhereCondEqual {
+			return nil, fmt.Errorf("where condition '%s' not supported", cond.Where)
+		}
+		if !hasField(sch, cond.Key) {
+			return nil, fmt.Errorf("table %s does not have the field %s", q.Table, cond.Key)
+		}
+		args = append(args, cond.Val)
+		where = append(where, fmt.Sprintf("%s = $%d", cond.Key, len(args)))
 	}
-	if q.Skip != 0 {
-		query += " OFFSET " + strconv.Itoa(int(q.Skip))
+	if len(where) != 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
 	}
 	if q.OrderBy != "" {
+		if !hasField(sch, q.OrderBy) {
+			return nil, fmt.Errorf("table %s does not have the field %s", q.Table, q.OrderBy)
+		}
 		query += " ORDER BY " + q.OrderBy
+		if q.Order == DescOrder {
+			query += " DESC"
+		}
 	}
-	if q.Order != AscOrder {
-		query += " DESC"
+	if q.First != 0 {
+		query += " LIMIT " + strconv.Itoa(int(q.First))
 	}
-	rows, err := s.db.Query(query)
+	if q.Skip != 0 {
+		query += " OFFSET " + strconv.Itoa(int(q.Skip))
+	}
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -162,13 +367,10 @@ func (s *State) GetObj2(table string, keys map[string]string) (*ResObj, error) {
 		return nil, fmt.Errorf("table not found %s", table)
 	}
 
-	kv := []string{}
-	for k, v := range keys {
-		kv = append(kv, fmt.Sprintf("%s = '%s'", k, v))
-	}
-	query := "SELECT * FROM " + table + " WHERE " + strings.Join(kv, " AND ")
+	where, args := whereKeys(keys, 1)
+	query := "SELECT " + selectColumns(sch) + " FROM " + table + " WHERE " + where
 
-	rows, err := s.db.Query(query)
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -190,7 +392,7 @@ func (s *State) GetObj(table, k, v string) (*ResObj, error) {
 		return nil, fmt.Errorf("table %s not found", table)
 	}
 
-	rows, err := s.db.Query("SELECT * FROM "+table+" WHERE "+k+" = $1", v)
+	rows, err := s.db.Query("SELECT "+selectColumns(sch)+" FROM "+table+" WHERE "+k+" = $1", v)
 	if err != nil {
 		return nil, err
 	}
@@ -204,6 +406,28 @@ func (s *State) GetObj(table, k, v string) (*ResObj, error) {
 	return obj, nil
 }
 
+// hasField returns true if the table has the field. The names of the
+// fields are checked since they are included in the query.
+func hasField(table *sdk.Table, name string) bool {
+	for _, f := range table.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectColumns returns the columns of the fields of the table. The columns
+// are selected by name since decodeObj matches them with the fields by position
+// and the columns added by a migration are at the end of the table.
+func selectColumns(table *sdk.Table) string {
+	cols := []string{}
+	for _, f := range table.Fields {
+		cols = append(cols, f.Name)
+	}
+	return strings.Join(cols, ", ")
+}
+
 func (s *State) decodeObj(rows *sql.Rows, table *sdk.Table) (*ResObj, error) {
 	cols, err := rows.Columns()
 	if err != nil {
@@ -237,9 +461,9 @@ func (s *State) decodeObj(rows *sql.Rows, table *sdk.Table) (*ResObj, error) {
 
 		case sdk.TypeUint:
 			raw := string(val.([]byte))
-			// make sure its an int
-			if _, err := strconv.Atoi(raw); err != nil {
-				return nil, err
+			// make sure its an int, it might be higher than 64 bits
+			if _, ok := new(big.Int).SetString(raw, 10); !ok {
+				return nil, fmt.Errorf("incorrect uint")
 			}
 			obj.Data[cols[i]] = raw
 
@@ -253,6 +477,30 @@ func (s *State) decodeObj(rows *sql.Rows, table *sdk.Table) (*ResObj, error) {
 			}
 			obj.Data[cols[i]] = raw
 
+		case sdk.TypeString, sdk.TypeHash:
+			obj.Data[cols[i]] = val.(string)
+
+		case sdk.TypeBool:
+			obj.Data[cols[i]] = strconv.FormatBool(val.(bool))
+
+		case sdk.TypeBytes:
+			// use the same hex format of the bytea type
+			obj.Data[cols[i]] = "\\x" + hex.EncodeToString(val.([]byte))
+
+		case sdk.TypeInt:
+			obj.Data[cols[i]] = strconv.FormatInt(val.(int64), 10)
+
+		case sdk.TypeTimestamp:
+			obj.Data[cols[i]] = val.(time.Time).UTC().Format(time.RFC3339Nano)
+
+		case sdk.TypeBigInt:
+			raw := string(val.([]byte))
+			// make sure its an integer
+			if _, ok := new(big.Int).SetString(raw, 10); !ok {
+				return nil, fmt.Errorf("incorrect bigint")
+			}
+			obj.Data[cols[i]] = raw
+
 		default:
 			return nil, fmt.Errorf("type not found")
 		}
@@ -260,57 +508,367 @@ func (s *State) decodeObj(rows *sql.Rows, table *sdk.Table) (*ResObj, error) {
 	return obj, nil
 }
 
+// UpsertTable creates the table or migrates it to the schema
 func (s *State) UpsertTable(t *sdk.Table) error {
-	// create the ddl
-	ddl := buildDDL(t)
-
-	if _, err := s.db.Exec(ddl); err != nil {
+	m, err := s.PlanTable(t)
+	if err != nil {
 		return err
 	}
+	return s.MigrateTable(m)
+}
+
+// UpsertReferences creates the foreign keys and the indexes of the references
+// of the table. It is called once all the tables of the provider exist since
+// the tables might reference each other.
+func (s *State) UpsertReferences(t *sdk.Table) error {
+	for _, field := range t.Fields {
+		if field.References == nil {
+			continue
+		}
+		for _, ddl := range buildReferenceDDL(t, field) {
+			if _, err := s.db.Exec(ddl); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
-func (s *State) ApplyDiff(obj []*protosdk.Diff, apply bool) error {
+// journalEntry is an entry in the undo journal with the values an object had
+// before a diff was applied
+type journalEntry struct {
+	Table    string `db:"tbl"`
+	Creation bool   `db:"creation"`
+	Deletion bool   `db:"deletion"`
+	Keys     string `db:"keys"`
+	Vals     string `db:"vals"`
+}
+
+// BlockDiff is the result of processing a block of a track
+type BlockDiff struct {
+	// Provider is the name of the provider of the track
+	Provider string
+
+	// Track is the name of the track
+	Track string
+
+	// Number and Hash of the block
+	Number uint64
+	Hash   web3.Hash
+
+	// Block is the header of the block
+	Block *sdk.Block
+
+	// Diffs are the changes in the entities
+	Diffs []*protosdk.Diff
+
+	// Transactions are the transactions to store
+	Transactions []*proto.Transaction
+
+	// Events are the logs of the block to archive
+	Events []*proto.Event
+
+	// Tracks are the new tracks created while processing the block
+	Tracks []*proto.Track
+
+	// DeadLetters are the events (or calls) skipped by the handlers
+	DeadLetters []*proto.DeadLetter
+}
+
+// ApplyDiff applies the diffs generated by the track at the given block. The
+// cursor of the track is moved to that block within the same transaction so
+// that a block is never applied twice.
+func (s *State) ApplyDiff(block *BlockDiff, apply bool) error {
+	if !apply {
+		return nil
+	}
 
-	txn, err := s.db.Begin()
+	txn, err := s.db.Beginx()
 	if err != nil {
 		return err
 	}
-	for _, diff := range obj {
+	defer txn.Rollback()
+
+	for _, diff := range block.Diffs {
+		// journal the values of the object before the diff is applied
+		if err := s.journalDiff(txn, block, diff); err != nil {
+			return err
+		}
+
+		// the values are parametrized since free-form
+		// strings (i.e. names) might include quotes
 		var query string
+		args := []interface{}{}
 		if diff.Creation {
 			// insert op
 			names := []string{}
 			vals := []string{}
 
 			for k, v := range diff.Keys {
+				args = append(args, v)
 				names = append(names, k)
-				vals = append(vals, "'"+v+"'")
+				vals = append(vals, fmt.Sprintf("$%d", len(args)))
 			}
 			for k, v := range diff.Vals {
+				args = append(args, v)
 				names = append(names, k)
-				vals = append(vals, "'"+v+"'")
+				vals = append(vals, fmt.Sprintf("$%d", len(args)))
 			}
 			query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", diff.Table, strings.Join(names, ", "), strings.Join(vals, ", "))
+		} else if diff.Deletion {
+			// delete op
+			where, whereArgs := whereKeys(diff.Keys, 1)
+			args = append(args, whereArgs...)
+			query = fmt.Sprintf("DELETE FROM %s WHERE %s", diff.Table, where)
 		} else {
 			// update op
 			vals := []string{}
 			for k, v := range diff.Vals {
-				vals = append(vals, fmt.Sprintf("%s = '%s'", k, v))
+				args = append(args, v)
+				vals = append(vals, fmt.Sprintf("%s = $%d", k, len(args)))
 			}
-			where := []string{}
-			for k, v := range diff.Keys {
-				where = append(where, fmt.Sprintf("%s = '%s'", k, v))
-			}
-			query = fmt.Sprintf("UPDATE %s SET %s WHERE %s", diff.Table, strings.Join(vals, ", "), strings.Join(where, " AND "))
+			where, whereArgs := whereKeys(diff.Keys, len(args)+1)
+			args = append(args, whereArgs...)
+			query = fmt.Sprintf("UPDATE %s SET %s WHERE %s", diff.Table, strings.Join(vals, ", "), where)
+		}
+
+		if _, err := txn.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+
+	// store the header of the block. If there was a reorg, the block
+	// of the canonical chain replaces the previous one.
+	if block.Block != nil {
+		if _, err := txn.Exec("INSERT INTO blocks (number, hash, parent_hash, timestamp) VALUES ($1, $2, $3, $4) ON CONFLICT (number) DO UPDATE SET hash = $2, parent_hash = $3, timestamp = $4", block.Block.Number, block.Block.Hash.String(), block.Block.ParentHash.String(), block.Block.Timestamp); err != nil {
+			return err
+		}
+	}
+
+	// archive the logs
+	for _, evnt := range block.Events {
+		if _, err := txn.NamedExec(upsertEventQuery, &archivedEvent{Track: block.Track, Event: evnt}); err != nil {
+			return err
+		}
+	}
+
+	// store the transactions. If there was a reorg, the transaction
+	// might be included in another block
+	for _, tx := range block.Transactions {
+		if _, err := txn.NamedExec(upsertTransactionQuery, tx); err != nil {
+			return err
 		}
+	}
+
+	// add the new tracks
+	for _, track := range block.Tracks {
+		if _, err := filterConfigFromTracker(track); err != nil {
+			return err
+		}
+		if _, err := txn.NamedExec(upsertTrackQuery, track); err != nil {
+			return err
+		}
+	}
 
-		if apply {
-			if _, err := txn.Exec(query); err != nil {
+	// record the events skipped by the failure policy
+	for _, letter := range block.DeadLetters {
+		if _, err := txn.NamedExec(insertDeadLetterQuery, letter); err != nil {
+			return err
+		}
+	}
+
+	// move the cursor of the track
+	if _, err := txn.Exec("UPDATE tracks SET lastblockhash = $1, lastblocknum = $2 WHERE name = $3", block.Hash.String(), block.Number, block.Track); err != nil {
+		return err
+	}
+
+	// prune the journal entries that are too old to be reverted. The logs
+	// of the tracker are pruned as well since they are only used to detect
+	// the reorgs, the logs of the applied blocks are archived in 'events'.
+	// The last log before the window is kept since the tracker walks the
+	// logs backwards until it finds one older than the reorg.
+	if block.Number > s.journalDepth {
+		if _, err := txn.Exec("DELETE FROM journal WHERE provider = $1 AND block_num < $2", block.Provider, block.Number-s.journalDepth); err != nil {
+			return err
+		}
+		if _, err := txn.Exec("DELETE FROM tracker_logs WHERE entry = $1 AND indx < (SELECT max(indx) FROM tracker_logs WHERE entry = $1 AND block_num < $2)", block.Track, block.Number-s.journalDepth); err != nil {
+			return err
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// journalDiff records the values of the object before the diff is applied. The
+// journal is kept per provider since its tracks write the same objects.
+func (s *State) journalDiff(txn *sqlx.Tx, block *BlockDiff, diff *protosdk.Diff) error {
+	if len(diff.Keys) == 0 {
+		// the object cannot be referenced without keys
+		return nil
+	}
+
+	prev := map[string]*string{}
+	if diff.Deletion {
+		// read the whole object so that it can be inserted again
+		found, err := journalRow(txn, diff, prev)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return nil
+		}
+	} else if !diff.Creation {
+		// read the current values of the fields that are going to change
+		names := []string{}
+		cols := []string{}
+		for k := range diff.Vals {
+			names = append(names, k)
+			// the values are journaled with the text representation
+			// of the column type (i.e. the hex format of the bytes)
+			cols = append(cols, k+"::text")
+		}
+		where, args := whereKeys(diff.Keys, 1)
+		query := fmt.Sprintf("SELECT %s FROM %s WHERE %s", strings.Join(cols, ", "), diff.Table, where)
+
+		rows, err := txn.Query(query, args...)
+		if err != nil {
+			return err
+		}
+		found := rows.Next()
+		if found {
+			values := make([]sql.NullString, len(names))
+			dest := make([]interface{}, len(names))
+			for i := range values {
+				dest[i] = &values[i]
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
 				return err
 			}
+			for i, name := range names {
+				if values[i].Valid {
+					val := values[i].String
+					prev[name] = &val
+				} else {
+					prev[name] = nil
+				}
+			}
+		}
+		rows.Close()
+		if !found {
+			// there is nothing to revert if the update does not match any object
+			return nil
+		}
+	}
+
+	keys, err := json.Marshal(diff.Keys)
+	if err != nil {
+		return err
+	}
+	vals, err := json.Marshal(prev)
+	if err != nil {
+		return err
+	}
+	if _, err := txn.Exec("INSERT INTO journal (provider, track, block_num, block_hash, tbl, creation, deletion, keys, vals) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)", block.Provider, block.Track, block.Number, block.Hash.String(), diff.Table, diff.Creation, diff.Deletion, string(keys), string(vals)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// journalRow reads all the columns of the object with the text representation
+// of the column type. It returns false if there is no object with the keys.
+func journalRow(txn *sqlx.Tx, diff *protosdk.Diff, row map[string]*string) (bool, error) {
+	where, args := whereKeys(diff.Keys, 1)
+	query := fmt.Sprintf("SELECT j.key, j.value FROM (SELECT * FROM %s WHERE %s) t, json_each_text(row_to_json(t)) j", diff.Table, where)
+
+	rows, err := txn.Query(query, args...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	found := false
+	for rows.Next() {
+		var name string
+		var value sql.NullString
+		if err := rows.Scan(&name, &value); err != nil {
+			return false, err
+		}
+		found = true
+		if value.Valid {
+			val := value.String
+			row[name] = &val
+		} else {
+			row[name] = nil
+		}
+	}
+	return found, rows.Err()
+}
+
+// journalBlock is a block with diffs in the undo journal
+type journalBlock struct {
+	Number uint64 `db:"block_num"`
+	Hash   string `db:"block_hash"`
+}
+
+// GetJournalBlocks returns the blocks at or after 'blockNum' in
+// which the tracks of the provider applied any diff
+func (s *State) GetJournalBlocks(provider string, blockNum uint64) ([]*journalBlock, error) {
+	var blocks []*journalBlock
+	if err := s.db.Select(&blocks, "SELECT DISTINCT block_num, block_hash FROM journal WHERE provider = $1 AND block_num >= $2 ORDER BY block_num", provider, blockNum); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// Revert undoes all the diffs applied by the tracks of the provider in the
+// stale blocks, in the reverse order in which they were applied since the
+// tracks write the same objects. The diffs applied in the blocks of the
+// canonical chain are kept, even if they are newer than the stale ones. The
+// logs and the dead letters of the track at or after the block 'blockNum'
+// are removed and its cursor is moved right before that block.
+func (s *State) Revert(provider, track string, blockNum uint64, stale []web3.Hash) error {
+	txn, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	hashes := []string{}
+	for _, hash := range stale {
+		hashes = append(hashes, hash.String())
+	}
+
+	var entries []*journalEntry
+	if err := txn.Select(&entries, "SELECT tbl, creation, deletion, keys, vals FROM journal WHERE provider = $1 AND block_hash = ANY($2) ORDER BY indx DESC", provider, pq.Array(hashes)); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := revertEntry(txn, entry); err != nil {
+			return err
 		}
 	}
+	if _, err := txn.Exec("DELETE FROM journal WHERE provider = $1 AND block_hash = ANY($2)", provider, pq.Array(hashes)); err != nil {
+		return err
+	}
+	if _, err := txn.Exec("DELETE FROM events WHERE track = $1 AND block_num >= $2", track, blockNum); err != nil {
+		return err
+	}
+	if _, err := txn.Exec("DELETE FROM dead_letters WHERE track = $1 AND block_num >= $2", track, blockNum); err != nil {
+		return err
+	}
+
+	// the hash of the new cursor is not known, it gets resolved by number on startup
+	var cursor uint64
+	if blockNum > 0 {
+		cursor = blockNum - 1
+	}
+	if _, err := txn.Exec("UPDATE tracks SET lastblockhash = '', lastblocknum = $1 WHERE name = $2 AND lastblocknum >= $3", cursor, track, blockNum); err != nil {
+		return err
+	}
 
 	if err := txn.Commit(); err != nil {
 		return err
@@ -318,21 +876,98 @@ func (s *State) ApplyDiff(obj []*protosdk.Diff, apply bool) error {
 	return nil
 }
 
+func revertEntry(txn *sqlx.Tx, entry *journalEntry) error {
+	var keys map[string]string
+	if err := json.Unmarshal([]byte(entry.Keys), &keys); err != nil {
+		return err
+	}
+
+	if entry.Creation {
+		// the object did not exist before
+		where, args := whereKeys(keys, 1)
+		_, err := txn.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", entry.Table, where), args...)
+		return err
+	}
+
+	var vals map[string]*string
+	if err := json.Unmarshal([]byte(entry.Vals), &vals); err != nil {
+		return err
+	}
+
+	if entry.Deletion {
+		// insert again the object with all the values it had
+		names := []string{}
+		params := []string{}
+		args := []interface{}{}
+		for k, v := range vals {
+			if v == nil {
+				continue
+			}
+			args = append(args, *v)
+			names = append(names, k)
+			params = append(params, fmt.Sprintf("$%d", len(args)))
+		}
+		_, err := txn.Exec(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", entry.Table, strings.Join(names, ", "), strings.Join(params, ", ")), args...)
+		return err
+	}
+	if len(vals) == 0 {
+		return nil
+	}
+
+	set := []string{}
+	args := []interface{}{}
+	for k, v := range vals {
+		args = append(args, v)
+		set = append(set, fmt.Sprintf("%s = $%d", k, len(args)))
+	}
+	where, whereArgs := whereKeys(keys, len(args)+1)
+	args = append(args, whereArgs...)
+
+	_, err := txn.Exec(fmt.Sprintf("UPDATE %s SET %s WHERE %s", entry.Table, strings.Join(set, ", "), where), args...)
+	return err
+}
+
+// whereKeys builds a parametrized where clause that matches all the keys. The
+// parameter placeholders start at 'indx'.
+func whereKeys(keys map[string]string, indx int) (string, []interface{}) {
+	where := []string{}
+	args := []interface{}{}
+	for k, v := range keys {
+		where = append(where, fmt.Sprintf("%s = $%d", k, indx+len(args)))
+		args = append(args, v)
+	}
+	return strings.Join(where, " AND "), args
+}
+
+// columnType returns the type of the column of the field in the DDL and
+// the same type as it is reported by the catalog of Postgresql
+func columnType(f *sdk.Field) (ddl string, catalog string) {
+	switch f.Type {
+	case sdk.TypeAddress, sdk.TypeString, sdk.TypeHash:
+		return "text", "text"
+	case sdk.TypeUint, sdk.TypeBigInt:
+		// enough digits for any 256 bits integer
+		return "numeric(78,0)", "numeric(78,0)"
+	case sdk.TypeDecimal:
+		return "decimal", "numeric"
+	case sdk.TypeBool:
+		return "boolean", "boolean"
+	case sdk.TypeBytes:
+		return "bytea", "bytea"
+	case sdk.TypeInt:
+		return "bigint", "bigint"
+	case sdk.TypeTimestamp:
+		return "timestamptz", "timestamp with time zone"
+	default:
+		panic(fmt.Sprintf("Not found: %d", f.Type))
+	}
+}
+
 func buildDDL(t *sdk.Table) string {
 	idFields := []string{}
 	fieldNames := []string{}
 	for _, f := range t.Fields {
-		var typ string
-		switch f.Type {
-		case sdk.TypeAddress:
-			typ = "text"
-		case sdk.TypeUint:
-			typ = "numeric"
-		case sdk.TypeDecimal:
-			typ = "decimal"
-		default:
-			panic(fmt.Sprintf("Not found: %d", f.Type))
-		}
+		typ, _ := columnType(f)
 		if f.ID {
 			idFields = append(idFields, f.Name)
 		}
@@ -346,3 +981,34 @@ func buildDDL(t *sdk.Table) string {
 
 	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", t.Name, strings.Join(fieldNames, ", "))
 }
+
+// maxIdentifierLen is the maximum length of an identifier in Postgresql
+const maxIdentifierLen = 63
+
+// referenceName returns the name of the constraint (or index) of a reference
+func referenceName(prefix string, t *sdk.Table, f *sdk.Field) string {
+	name := fmt.Sprintf("%s_%s_%s", prefix, t.Name, f.Name)
+	if len(name) > maxIdentifierLen {
+		// Postgresql truncates the identifiers, do it here so that
+		// the existence of the constraint can be checked by name
+		name = name[:maxIdentifierLen]
+	}
+	return strings.ToLower(name)
+}
+
+// buildReferenceDDL returns the statements to create the foreign key and the
+// index of a field that references another table. The constraints cannot be
+// created with 'IF NOT EXISTS' so it is checked in the catalog.
+func buildReferenceDDL(t *sdk.Table, f *sdk.Field) []string {
+	ref := f.References
+
+	fkName := referenceName("fk", t, f)
+	constraint := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)", t.Name, fkName, f.Name, ref.Table, ref.Field)
+	if ref.Deferred {
+		constraint += " DEFERRABLE INITIALLY DEFERRED"
+	}
+
+	fk := fmt.Sprintf("DO $$ BEGIN IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = '%s') THEN %s; END IF; END $$", fkName, constraint)
+	index := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", referenceName("idx", t, f), t.Name, f.Name)
+	return []string{fk, index}
+}
@@ -6,6 +6,12 @@
 // indexer/migrations/03-events.sql
 // indexer/migrations/04-tracker.sql
 // indexer/migrations/05-indexer.sql
+// indexer/migrations/06-journal.sql
+// indexer/migrations/07-dead-letters.sql
+// indexer/migrations/08-schema-versions.sql
+// indexer/migrations/09-journal-deletion.sql
+// indexer/migrations/10-journal-provider.sql
+// indexer/migrations/11-tracker-logs-block-num.sql
 package indexer
 
 import (
@@ -82,7 +88,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _indexerMigrations01BlockSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd2\xd7\xe2\x72\x0e\x72\x75\x0c\x71\x55\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\xf0\xf3\x0f\x51\x70\x8d\xf0\x0c\x0e\x09\x56\x48\xca\xc9\x4f\xce\x56\xd0\xe0\xe2\xd2\xe4\xd2\xd2\x07\x04\x00\x00\xff\xff\x6e\xc1\x67\xd7\x2b\x00\x00\x00")
+var _indexerMigrations01BlockSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x0e\x72\x75\x0c\x71\x55\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\xf0\xf3\x0f\x51\x70\x8d\xf0\x0c\x0e\x09\x56\x48\xca\xc9\x4f\xce\x2e\x56\xd0\xe0\x52\x50\x50\x50\xc8\x2b\xcd\x4d\x4a\x2d\x52\x00\x83\xbc\xd2\xdc\xd4\xa2\xcc\x64\x85\x50\x3f\xcf\xc0\x50\x57\x1d\xb0\x7c\x46\x62\x71\x86\x02\x14\x94\xa4\x56\x94\x40\x44\x0b\x12\x8b\x52\xf3\x4a\xe2\xc1\x92\x08\xd1\x92\xcc\xdc\xd4\xe2\x92\xc4\xdc\x02\x84\x59\x5c\x9a\xd6\x5c\x00\x00\x00\x00\xff\xff\x03\x00\x05\x4e\x08\x13\x8b\x00\x00\x00")
 
 func indexerMigrations01BlockSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -97,12 +103,12 @@ func indexerMigrations01BlockSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "indexer/migrations/01-block.sql", size: 43, mode: os.FileMode(436), modTime: time.Unix(1620822980, 0)}
+	info := bindataFileInfo{name: "indexer/migrations/01-block.sql", size: 139, mode: os.FileMode(436), modTime: time.Unix(1792316357, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _indexerMigrations02TransactionSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\x8e\x4d\x0a\xc2\x30\x10\x85\xf7\x39\xc5\x2c\xb5\x08\x3d\x43\x95\x08\x05\x51\xb0\x59\xb8\x0b\x31\x8d\x6d\xb0\x9d\x94\x64\x22\x3d\xbe\xd0\x1f\x6c\x6d\x96\xdf\x7b\x79\xf3\xa5\x09\x3b\xdd\x79\x26\x38\x88\xec\x78\xe1\x90\x9f\xe1\x7a\x13\xc0\x1f\x79\x21\x0a\x20\xaf\x30\x28\x4d\xd6\x61\x80\x1d\x03\x00\xa8\x55\xa8\x61\x7a\x64\x7a\x3a\x0c\x14\x1d\x6a\x33\x51\x8c\xad\xf1\x56\x8f\xc1\xb3\x71\xfa\x2d\xc7\x4f\xbf\xfa\x48\x31\xb6\xff\x75\xea\xa5\xc5\xd2\xf4\x9b\x9d\x97\x77\xad\x54\x65\xe9\x57\x67\xc9\xcd\x6c\x49\x3f\xaa\x89\x66\xa3\x58\xa9\x30\x7b\xaf\xa7\x2b\x15\x64\xe7\xed\xe0\xbf\x0a\x2c\x76\x91\x16\x3b\x6c\xcf\x92\xf4\x1b\x00\x00\xff\xff\xe2\xb3\x9b\x39\x31\x01\x00\x00")
+var _indexerMigrations02TransactionSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x90\xcd\x4e\x85\x30\x10\x85\xf7\x7d\x8a\x59\xde\x9b\xf8\x06\x77\x85\x5a\x93\x26\xa6\x44\x29\x09\xbb\xa6\x96\x0a\x8d\x30\x25\xfd\x31\x3c\xbe\x41\x8a\x20\x26\x76\xf9\x7d\x73\x4e\x27\xf3\xf0\x4a\x0b\x41\x41\x14\xf7\xcf\x14\xd8\x13\xf0\x52\x00\x6d\x58\x25\x2a\x88\x5e\x61\x50\x3a\x5a\x87\x01\x2e\x04\x00\xa0\x57\xa1\x87\xfc\xa2\x99\x23\xd4\x9c\xbd\xd4\xf4\xee\x5b\xa2\x43\x6d\xb2\xc4\x34\x1a\x6f\xf5\x2a\xde\x06\xa7\x3f\xe4\x9a\x5d\x52\x47\x8a\x69\x3c\x8f\xc7\x59\x5a\x6c\xcd\xfc\xa7\xe7\xdd\xbb\x51\xaa\xb6\xf5\xf9\xf7\x3c\xee\x36\x76\xa4\x9f\x6a\x48\xdb\x32\x3b\xed\x54\xc8\xec\x54\xdd\xa9\x20\x27\x6f\xb5\x39\x0b\x8b\x53\x8a\x39\xb1\xf4\x90\xeb\x8d\x90\x7c\x34\xc6\x1f\x69\xf3\xcf\xd1\xe4\xbe\x70\xc9\x7f\x99\xcb\x8f\xb9\xde\xc8\x17\x00\x00\x00\xff\xff\x03\x00\x60\x2a\xf1\xa3\x83\x01\x00\x00")
 
 func indexerMigrations02TransactionSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -117,12 +123,12 @@ func indexerMigrations02TransactionSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "indexer/migrations/02-transaction.sql", size: 305, mode: os.FileMode(436), modTime: time.Unix(1620822991, 0)}
+	info := bindataFileInfo{name: "indexer/migrations/02-transaction.sql", size: 387, mode: os.FileMode(436), modTime: time.Unix(1792316410, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _indexerMigrations03EventsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\xd0\xcd\x6a\x03\x21\x14\x05\xe0\xb5\x3e\xc5\x59\x26\x21\x90\x07\xc8\x2a\x6d\x2d\x0c\x94\x09\x34\x2e\xb2\x1b\xcc\x78\x4b\xa4\x8e\x96\xd1\x09\x3e\x7e\xc9\x38\xbf\x10\x77\xe7\xe3\x78\xb9\xdc\xc3\x8e\xbf\x7f\x8b\x93\x14\x90\xa7\xb7\x2f\x81\xe2\x13\xe5\x59\x42\x5c\x8b\x8b\xbc\x80\x1e\xe4\x62\xc0\x86\x03\xc8\x01\xf9\x45\x4a\x71\xcf\x99\x71\x3a\x81\x31\xd7\x35\xd4\x9a\x7a\xcf\xd9\x4f\x67\x6d\xd5\x2b\x30\x6b\x4c\x4f\xa3\x04\xb6\xb2\xbb\x0a\x77\xb0\x61\xd4\xcd\xfa\xfa\xb7\x72\x5d\xb3\x2c\x65\x5c\xf5\x94\xd6\x2d\x85\x30\xe5\xe8\xff\x4c\x6d\xf4\x72\xab\x9e\x02\xd8\x58\xd1\x2a\xaa\x29\x3d\x7b\x2d\x35\xfe\x41\xf9\xcf\xcd\x7b\x4b\xca\xf1\xed\x91\x8f\x97\x28\xca\x0f\x71\x7d\x79\x89\x6a\xde\xf2\x5c\x0e\xb6\x99\x6c\x7b\xe4\xbb\xc3\x7f\x00\x00\x00\xff\xff\x8e\xa2\xa7\x95\x51\x01\x00\x00")
+var _indexerMigrations03EventsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\xd1\x6a\xc3\x20\x18\x85\xef\x7d\x8a\x73\x99\x40\xde\xa0\x57\xdd\xe6\x20\x30\x2c\x5b\x2d\xf4\x2e\x58\xfd\x59\xa5\x51\x87\x9a\x92\xc7\x1f\xc4\xd0\x80\x6d\x2e\xbf\x73\x72\xf8\xfd\xde\x7f\xf8\x5e\x72\xc8\xfd\xdb\x17\x47\xff\x09\x71\x90\xe0\xe7\xfe\x28\x8f\xa0\x3b\xf9\x9c\xd0\x30\x00\xc8\x51\xe9\x1b\xca\x97\x69\xce\xdd\x42\xc7\xf0\x3b\x58\x6f\x68\x06\xe0\x27\x47\xd1\xea\x12\xe4\xf9\xc1\x9f\x82\xab\x4a\xd7\x6a\xe7\x32\x06\x7d\x1b\xfc\xe4\xea\x7a\x09\xca\x1f\x5b\x5d\x19\x13\x29\xa5\x6a\x24\x87\x3f\xab\xad\x79\x45\x53\x7d\xb8\x51\x59\x01\x35\x5d\x5e\xfc\x44\x23\xb9\x70\xa7\xb2\x7b\x09\x61\x24\xe5\x4b\xfd\x24\xfa\xef\x13\x47\xb3\xb8\xe9\xd6\x5b\xfd\xe4\xba\xcd\x4b\xcb\xda\x1d\x63\xab\xe4\x5e\x7c\xf0\xf3\x4b\xc9\xc3\x26\xe0\x20\x56\xd6\x3c\x58\xbb\x63\xff\x00\x00\x00\xff\xff\x03\x00\xa8\x00\x79\xcf\xa7\x01\x00\x00")
 
 func indexerMigrations03EventsSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -137,12 +143,12 @@ func indexerMigrations03EventsSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "indexer/migrations/03-events.sql", size: 337, mode: os.FileMode(436), modTime: time.Unix(1620822989, 0)}
+	info := bindataFileInfo{name: "indexer/migrations/03-events.sql", size: 423, mode: os.FileMode(436), modTime: time.Unix(1792316486, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _indexerMigrations04TrackerSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\xce\x41\xaa\xc2\x30\x10\xc6\xf1\x7d\x4e\xf1\x2d\xdf\x03\x6f\xe0\xaa\x95\x88\x05\xa9\x68\x53\x70\x27\x63\x1a\xb1\xb4\x49\x24\x99\x82\xde\x5e\x68\xb0\x12\x70\x96\xff\x1f\xcc\x8c\xd8\x9c\x64\xa1\x24\x54\x51\xee\x25\xaa\x2d\xea\x83\x82\x3c\x57\x8d\x6a\xc0\x81\xf4\x10\xf1\x27\x00\xc0\x91\x35\x58\x86\xcd\x93\xd1\xd6\xd5\xb1\x95\xab\x99\x6f\xc1\xdb\x0b\x75\x5d\x58\x38\x75\xf6\xdf\x9a\xf7\x47\xaf\xb3\x75\xa9\x8f\x14\xb9\x1c\xbd\x1e\x76\x14\xef\xbf\x7a\x3d\x59\xc0\x4d\xd6\x84\x5e\x27\x8a\x4c\x21\xd9\xfc\x67\x46\x2f\xa7\x4d\xf7\xb9\x72\xf5\x7e\x34\xe4\xc4\xff\x5a\xbc\x03\x00\x00\xff\xff\x07\xe3\xb8\xc9\xf7\x00\x00\x00")
+var _indexerMigrations04TrackerSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x54\xc1\x6e\xe2\x30\x10\xbd\xfb\x2b\xde\xad\x45\x6a\xbf\xa0\xa7\xb4\xa4\x5a\xa4\x6c\xaa\x6d\x13\x69\x6f\xc8\xd8\x03\x44\x38\x36\xb2\x1d\x14\xfe\x7e\x95\x38\x49\x85\xd9\x16\x4a\x8e\xef\x8d\xe7\xcd\xbc\xc9\x0c\x7b\x79\x4f\x93\x22\x45\x91\x3c\x67\x29\x16\xaf\xc8\xdf\x0a\xa4\x7f\x17\x1f\xc5\x07\xbc\xe5\x62\xe7\x70\xcf\x00\x40\xf3\x9a\x30\x7d\x9e\x5a\x8f\x32\x5f\xfc\x29\xd3\x87\x9e\x5e\x5b\x53\x2f\xb9\x94\x76\xa2\x03\xee\xcd\x27\x7a\x8a\xef\x2b\x01\x9c\xe1\x8a\x3b\xff\xac\x8c\xd8\xfd\xe2\x6e\xfb\x3f\x3c\x6f\x6a\x40\x37\x35\xd9\x4a\x84\x54\xce\x73\x1b\x38\x20\xa6\x8e\x5a\x90\x1c\x55\x56\xc6\x28\xe2\x7a\x28\x80\xea\xbd\xe2\x7e\xe8\xa9\x13\xea\x5b\xcf\xcb\x2c\xc3\x3c\x7d\x4d\xca\xac\xc0\xdd\x5d\x88\xdd\x5b\x73\xa8\x24\xd9\x6b\x62\x05\x57\xca\x21\x92\x3c\x0f\x5f\x73\xe5\x28\x64\x5f\x75\xa5\xbb\xeb\x5e\xb0\xd9\x13\x63\x8f\x8f\xe3\x68\x84\x25\xee\x49\x62\x45\x6b\x63\x09\x7e\x4b\x90\x47\xcd\xeb\x4a\xc0\x99\xc6\x0a\x72\x2c\xc9\x8a\xf4\x7d\x18\xef\xf0\x2a\x99\xcf\xf1\xf2\x96\x95\xbf\xf3\x78\xe0\xa3\x27\x5f\xb5\xf8\x9d\xb8\x6d\xb4\xae\xf4\x06\x8e\x0e\x64\xb9\x9a\x4c\xfb\x59\x09\x93\xd5\xb7\x94\xd0\xf5\xdf\xf9\x1f\xec\xf9\xa9\x74\xf7\xd2\x5d\xf0\xff\x92\x7a\x3f\xcb\xdb\xe4\x87\xdf\xe0\xa2\xfe\xa5\x85\x25\xbb\xdc\x1d\x86\xa5\xdd\xd1\xf1\xeb\x95\x3d\x70\x35\x72\x3d\xc9\x66\xd7\x65\x57\x66\x33\x1e\x05\xd2\xde\x1e\xcf\x76\xb8\xd2\xb2\x1d\xc0\xd3\x75\x54\x66\xb3\xac\xb4\xa4\x36\x26\x7c\x3b\xe1\x67\xc4\xb6\x3b\x04\xa7\x02\xbd\x55\x4b\xdd\xd4\x71\x78\x20\xc2\x8b\xcf\xf0\xee\x00\x91\x73\x51\x92\xfe\x02\x39\x44\xa8\xe4\x9e\x03\x31\x1a\x6c\xc3\x7d\xdf\xef\x03\x2a\x2d\xdb\x19\x9b\x3d\xb1\x7f\x00\x00\x00\xff\xff\x03\x00\x5f\xb3\xd7\x8d\x3e\x05\x00\x00")
 
 func indexerMigrations04TrackerSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -157,7 +163,7 @@ func indexerMigrations04TrackerSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "indexer/migrations/04-tracker.sql", size: 247, mode: os.FileMode(436), modTime: time.Unix(1620823212, 0)}
+	info := bindataFileInfo{name: "indexer/migrations/04-tracker.sql", size: 1342, mode: os.FileMode(436), modTime: time.Unix(1792316758, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -182,6 +188,126 @@ func indexerMigrations05IndexerSql() (*asset, error) {
 	return a, nil
 }
 
+var _indexerMigrations06JournalSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x8f\xb1\x0a\x83\x30\x14\x45\xf7\x7c\xc5\x1d\x15\xfc\x03\x27\xdb\xa6\x20\x14\x85\x9a\xc1\x4d\x92\x34\x94\xd4\x98\x40\x8c\xc5\xfe\x7d\xa9\xa6\x48\xb1\x6f\xbc\xe7\x0c\xe7\x1d\xaf\xb4\x60\x14\xac\x38\x5c\x28\xca\x33\xaa\x9a\x81\xb6\x65\xc3\x1a\x3c\xdc\xe4\x2d\x37\x48\x08\x00\x68\x7b\x9b\x11\x4f\xe8\xfb\xa8\xbc\xe6\x26\x5b\x50\xf0\x5c\xf6\x11\x05\x35\x87\x75\x15\xc6\xc9\xbe\xb3\xd3\x00\xc0\x4e\x83\xf2\x5a\x46\x5d\x18\x60\xa7\x4b\xaf\x78\xd0\xce\x7e\x66\xe1\x9c\x51\xdc\xae\x7a\xaf\x5e\xe3\x5e\x7f\x72\xf3\xb3\x92\x34\x27\x24\x3e\x53\x56\x27\xda\xfe\x7f\xa6\x5b\x5a\xbb\xad\xad\xae\xbe\x28\x59\x50\xb6\x75\xa7\x39\x79\x03\x00\x00\xff\xff\x03\x00\x31\x4a\xf8\x7f\x1e\x01\x00\x00")
+
+func indexerMigrations06JournalSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_indexerMigrations06JournalSql,
+		"indexer/migrations/06-journal.sql",
+	)
+}
+
+func indexerMigrations06JournalSql() (*asset, error) {
+	bytes, err := indexerMigrations06JournalSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "indexer/migrations/06-journal.sql", size: 286, mode: os.FileMode(436), modTime: time.Unix(1792315720, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _indexerMigrations07DeadLettersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x90\xc1\x6a\x84\x30\x14\x45\xf7\xf9\x8a\xb7\x1c\x61\xfe\xc0\xd5\xb4\x4d\x41\x28\x0e\x74\xb2\x70\x17\xa2\x79\x68\x30\x26\xf6\x25\x16\xfb\xf7\x45\xb1\x16\x23\x94\xee\x2e\xf7\x1e\xee\xe2\x3c\xbf\xf3\x9b\xe0\x20\x6e\x4f\x6f\x1c\x8a\x57\x28\xef\x02\x78\x55\x3c\xc4\x03\x34\x2a\x2d\x2d\xc6\x88\x14\xe0\xc2\x00\x00\x46\xf2\x9f\x46\x23\x2d\x39\xe2\x1c\xaf\x6b\x1b\x49\x35\xfd\x12\x0e\x6d\x6d\x7d\xd3\x4b\x37\x0d\x00\xe0\xa6\x01\xc9\x34\x1b\x3e\xcb\x4e\x85\x2e\xc1\xad\x6f\xa5\x71\x1a\xe7\x13\xbe\xbc\x23\x25\xb8\xd2\x9a\x30\x84\xa4\x45\x22\x4f\x32\x7e\x8d\x78\x6e\x87\xd0\x1e\xd8\x8f\x49\x91\x72\xd1\x38\xd4\x50\x7b\x6f\x51\x39\x96\xe5\x8c\x6d\x46\x8a\xf2\x85\x57\x7f\x18\x91\xbb\x8b\x7b\x79\x18\x2e\x3f\x43\x96\xff\xfb\x6b\x35\x28\x7f\x8d\xa5\x97\xeb\x7e\x85\x1d\xc8\x72\xf6\x0d\x00\x00\xff\xff\x03\x00\xff\x70\x53\x8b\xb9\x01\x00\x00")
+
+func indexerMigrations07DeadLettersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_indexerMigrations07DeadLettersSql,
+		"indexer/migrations/07-dead-letters.sql",
+	)
+}
+
+func indexerMigrations07DeadLettersSql() (*asset, error) {
+	bytes, err := indexerMigrations07DeadLettersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "indexer/migrations/07-dead-letters.sql", size: 441, mode: os.FileMode(436), modTime: time.Unix(1792318813, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _indexerMigrations08SchemaVersionsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x7a\x00\x85\xff\x43\x52\x45\x41\x54\x45\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x4e\x4f\x54\x20\x45\x58\x49\x53\x54\x53\x20\x73\x63\x68\x65\x6d\x61\x5f\x76\x65\x72\x73\x69\x6f\x6e\x73\x20\x28\x0a\x20\x20\x20\x20\x74\x62\x6c\x20\x20\x20\x20\x20\x20\x20\x74\x65\x78\x74\x20\x50\x52\x49\x4d\x41\x52\x59\x20\x4b\x45\x59\x2c\x0a\x20\x20\x20\x20\x76\x65\x72\x73\x69\x6f\x6e\x20\x20\x20\x6e\x75\x6d\x65\x72\x69\x63\x2c\x0a\x20\x20\x20\x20\x73\x69\x67\x6e\x61\x74\x75\x72\x65\x20\x74\x65\x78\x74\x0a\x29\x3b\x0a\x00\x00\x00\xff\xff\x03\x00\x27\x32\x8a\x74\x7a\x00\x00\x00")
+
+func indexerMigrations08SchemaVersionsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_indexerMigrations08SchemaVersionsSql,
+		"indexer/migrations/08-schema-versions.sql",
+	)
+}
+
+func indexerMigrations08SchemaVersionsSql() (*asset, error) {
+	bytes, err := indexerMigrations08SchemaVersionsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "indexer/migrations/08-schema-versions.sql", size: 122, mode: os.FileMode(436), modTime: time.Unix(1792319995, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _indexerMigrations09JournalDeletionSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x4d\x00\xb2\xff\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x6a\x6f\x75\x72\x6e\x61\x6c\x20\x41\x44\x44\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x4e\x4f\x54\x20\x45\x58\x49\x53\x54\x53\x20\x64\x65\x6c\x65\x74\x69\x6f\x6e\x20\x62\x6f\x6f\x6c\x65\x61\x6e\x20\x44\x45\x46\x41\x55\x4c\x54\x20\x66\x61\x6c\x73\x65\x3b\x0a\x00\x00\x00\xff\xff\x03\x00\x85\x50\xe9\x94\x4d\x00\x00\x00")
+
+func indexerMigrations09JournalDeletionSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_indexerMigrations09JournalDeletionSql,
+		"indexer/migrations/09-journal-deletion.sql",
+	)
+}
+
+func indexerMigrations09JournalDeletionSql() (*asset, error) {
+	bytes, err := indexerMigrations09JournalDeletionSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "indexer/migrations/09-journal-deletion.sql", size: 77, mode: os.FileMode(436), modTime: time.Unix(1792320726, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _indexerMigrations10JournalProviderSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x90\x41\x6e\x83\x30\x10\x45\xf7\x9c\x62\x96\xad\xd4\x70\x01\x94\x05\x0d\x8e\x8a\xe4\x98\x0a\x8c\x9a\x1d\x32\x61\x2a\xd2\x80\x89\xc6\x26\xed\xf1\xab\x12\xb0\xd3\xa8\x9b\x2e\xe7\x0f\xef\xbf\xc1\x31\x97\x2c\x07\x19\x3f\x73\x06\x1f\xc3\x48\x5a\x75\x10\x27\x09\x6c\x32\x5e\xee\x04\xa4\x5b\x10\x99\x04\xb6\x4f\x0b\x59\xc0\x99\x86\xcb\xb1\x41\x02\x8b\x5f\x36\x0a\xfe\xc5\xd6\xdd\x70\x38\x55\xad\x32\xed\x4c\x07\xab\x15\xd8\x16\x01\xb5\xa5\x23\x9a\xa5\x01\x1b\xa8\xf1\x7d\x20\x04\x45\x08\x84\x17\x24\x8b\x0d\x9c\x91\xbc\x5e\x19\xf8\xc4\xae\x0b\xca\xd7\x24\x96\xde\x5d\x30\xe9\xbf\x59\x83\x25\x75\x38\x99\xd0\x25\xdb\x3c\xdb\xcd\x21\xbc\xbd\xb0\xdc\x81\xe1\x14\x7a\x42\xab\x1e\x21\x16\x89\xdb\xbb\x8a\xb4\x00\x51\x72\x1e\xfd\x65\xbe\xf9\xc1\xf5\x75\x30\xe1\x34\x4d\xde\x6b\x70\xe7\x9d\xc2\x4a\x8f\xbd\x27\xf4\xd8\xd7\x48\xbf\xec\x37\xc5\xce\x1f\x6c\x72\xf6\x73\x40\x2a\x12\xb6\xbf\x7b\xe9\x99\xab\x96\xab\x2b\xaf\xc9\xc4\xd2\xfa\xb0\x6c\x9f\xe6\xc3\xf5\xd8\x3f\x46\xc1\x37\x00\x00\x00\xff\xff\x03\x00\x38\xf9\xa3\xd2\x0f\x02\x00\x00")
+
+func indexerMigrations10JournalProviderSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_indexerMigrations10JournalProviderSql,
+		"indexer/migrations/10-journal-provider.sql",
+	)
+}
+
+func indexerMigrations10JournalProviderSql() (*asset, error) {
+	bytes, err := indexerMigrations10JournalProviderSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "indexer/migrations/10-journal-provider.sql", size: 527, mode: os.FileMode(436), modTime: time.Unix(1792321223, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _indexerMigrations11TrackerLogsBlockNumSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x5b\x00\xa4\xff\x43\x52\x45\x41\x54\x45\x20\x49\x4e\x44\x45\x58\x20\x49\x46\x20\x4e\x4f\x54\x20\x45\x58\x49\x53\x54\x53\x20\x74\x72\x61\x63\x6b\x65\x72\x5f\x6c\x6f\x67\x73\x5f\x65\x6e\x74\x72\x79\x5f\x62\x6c\x6f\x63\x6b\x5f\x6e\x75\x6d\x20\x4f\x4e\x20\x74\x72\x61\x63\x6b\x65\x72\x5f\x6c\x6f\x67\x73\x28\x65\x6e\x74\x72\x79\x2c\x20\x62\x6c\x6f\x63\x6b\x5f\x6e\x75\x6d\x29\x3b\x0a\x00\x00\x00\xff\xff\x03\x00\x86\x34\x9a\x16\x5b\x00\x00\x00")
+
+func indexerMigrations11TrackerLogsBlockNumSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_indexerMigrations11TrackerLogsBlockNumSql,
+		"indexer/migrations/11-tracker-logs-block-num.sql",
+	)
+}
+
+func indexerMigrations11TrackerLogsBlockNumSql() (*asset, error) {
+	bytes, err := indexerMigrations11TrackerLogsBlockNumSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "indexer/migrations/11-tracker-logs-block-num.sql", size: 91, mode: os.FileMode(436), modTime: time.Unix(1792321359, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -234,11 +360,17 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"indexer/migrations/01-block.sql":       indexerMigrations01BlockSql,
-	"indexer/migrations/02-transaction.sql": indexerMigrations02TransactionSql,
-	"indexer/migrations/03-events.sql":      indexerMigrations03EventsSql,
-	"indexer/migrations/04-tracker.sql":     indexerMigrations04TrackerSql,
-	"indexer/migrations/05-indexer.sql":     indexerMigrations05IndexerSql,
+	"indexer/migrations/01-block.sql":                  indexerMigrations01BlockSql,
+	"indexer/migrations/02-transaction.sql":            indexerMigrations02TransactionSql,
+	"indexer/migrations/03-events.sql":                 indexerMigrations03EventsSql,
+	"indexer/migrations/04-tracker.sql":                indexerMigrations04TrackerSql,
+	"indexer/migrations/05-indexer.sql":                indexerMigrations05IndexerSql,
+	"indexer/migrations/06-journal.sql":                indexerMigrations06JournalSql,
+	"indexer/migrations/07-dead-letters.sql":           indexerMigrations07DeadLettersSql,
+	"indexer/migrations/08-schema-versions.sql":        indexerMigrations08SchemaVersionsSql,
+	"indexer/migrations/09-journal-deletion.sql":       indexerMigrations09JournalDeletionSql,
+	"indexer/migrations/10-journal-provider.sql":       indexerMigrations10JournalProviderSql,
+	"indexer/migrations/11-tracker-logs-block-num.sql": indexerMigrations11TrackerLogsBlockNumSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -284,11 +416,17 @@ type bintree struct {
 var _bintree = &bintree{nil, map[string]*bintree{
 	"indexer": &bintree{nil, map[string]*bintree{
 		"migrations": &bintree{nil, map[string]*bintree{
-			"01-block.sql":       &bintree{indexerMigrations01BlockSql, map[string]*bintree{}},
-			"02-transaction.sql": &bintree{indexerMigrations02TransactionSql, map[string]*bintree{}},
-			"03-events.sql":      &bintree{indexerMigrations03EventsSql, map[string]*bintree{}},
-			"04-tracker.sql":     &bintree{indexerMigrations04TrackerSql, map[string]*bintree{}},
-			"05-indexer.sql":     &bintree{indexerMigrations05IndexerSql, map[string]*bintree{}},
+			"01-block.sql":                  &bintree{indexerMigrations01BlockSql, map[string]*bintree{}},
+			"02-transaction.sql":            &bintree{indexerMigrations02TransactionSql, map[string]*bintree{}},
+			"03-events.sql":                 &bintree{indexerMigrations03EventsSql, map[string]*bintree{}},
+			"04-tracker.sql":                &bintree{indexerMigrations04TrackerSql, map[string]*bintree{}},
+			"05-indexer.sql":                &bintree{indexerMigrations05IndexerSql, map[string]*bintree{}},
+			"06-journal.sql":                &bintree{indexerMigrations06JournalSql, map[string]*bintree{}},
+			"07-dead-letters.sql":           &bintree{indexerMigrations07DeadLettersSql, map[string]*bintree{}},
+			"08-schema-versions.sql":        &bintree{indexerMigrations08SchemaVersionsSql, map[string]*bintree{}},
+			"09-journal-deletion.sql":       &bintree{indexerMigrations09JournalDeletionSql, map[string]*bintree{}},
+			"10-journal-provider.sql":       &bintree{indexerMigrations10JournalProviderSql, map[string]*bintree{}},
+			"11-tracker-logs-block-num.sql": &bintree{indexerMigrations11TrackerLogsBlockNumSql, map[string]*bintree{}},
 		}},
 	}},
 }}
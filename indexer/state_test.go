@@ -2,7 +2,10 @@ package indexer
 
 import (
 	"fmt"
+	"math/big"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/ory/dockertest/v3"
@@ -46,6 +49,61 @@ func setupPostgresql(t *testing.T) (*sqlx.DB, func()) {
 	return db, cleanup
 }
 
+func TestState_MigrationNames(t *testing.T) {
+	names := migrationNames()
+	assert.Len(t, names, len(AssetNames()))
+	assert.Equal(t, "indexer/migrations/01-block.sql", names[0])
+	assert.True(t, sort.StringsAreSorted(names))
+}
+
+func TestState_MigrateEmpty(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	// the migrations run in order in an empty database
+	_, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	var columns []string
+	assert.NoError(t, db.Select(&columns, "SELECT column_name FROM information_schema.columns WHERE table_name = 'journal' AND column_name IN ('deletion', 'provider', 'block_hash') ORDER BY column_name"))
+	assert.Equal(t, []string{"block_hash", "deletion", "provider"}, columns)
+
+	// and again when the server restarts
+	_, err = newStateWithDB(db)
+	assert.NoError(t, err)
+}
+
+func TestState_MigrateJournalProvider(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	// the database of a version before the journal had the provider
+	for _, name := range migrationNames() {
+		if name >= "indexer/migrations/10" {
+			break
+		}
+		_, err := db.Exec(string(MustAsset(name)))
+		assert.NoError(t, err)
+	}
+	_, err := db.Exec("INSERT INTO tracks (name, provider) VALUES ('track', 'provider')")
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO blocks (number, hash) VALUES (1, '0x1')")
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO journal (track, block_num, tbl, creation, keys, vals) VALUES ('track', 1, 'tbl', true, '', '')")
+	assert.NoError(t, err)
+
+	_, err = newStateWithDB(db)
+	assert.NoError(t, err)
+
+	var entry struct {
+		Provider  string `db:"provider"`
+		BlockHash string `db:"block_hash"`
+	}
+	assert.NoError(t, db.Get(&entry, "SELECT provider, block_hash FROM journal"))
+	assert.Equal(t, "provider", entry.Provider)
+	assert.Equal(t, "0x1", entry.BlockHash)
+}
+
 func TestState_SchemaDDL(t *testing.T) {
 	db, close := setupPostgresql(t)
 	defer close()
@@ -118,7 +176,204 @@ func TestState_Diff(t *testing.T) {
 			},
 		},
 	}
-	assert.NoError(t, s.ApplyDiff(diff, true))
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, Diffs: diff}, true))
+}
+
+func TestState_Revert(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	tb := &sdk.Table{
+		Name: "tname",
+		Fields: []*sdk.Field{
+			{
+				Name: "a",
+				Type: sdk.TypeAddress,
+				ID:   true,
+			},
+			{
+				Name: "b",
+				Type: sdk.TypeUint,
+			},
+		},
+	}
+	assert.NoError(t, s.UpsertTable(tb))
+
+	// block 1 creates the object
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, Hash: web3.Hash{0x1}, Diffs: []*protosdk.Diff{
+		{
+			Creation: true,
+			Table:    "tname",
+			Keys: map[string]string{
+				"a": "a",
+			},
+			Vals: map[string]string{
+				"b": "1",
+			},
+		},
+	}}, true))
+
+	// block 2 updates the object
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 2, Hash: web3.Hash{0x2}, Diffs: []*protosdk.Diff{
+		{
+			Table: "tname",
+			Keys: map[string]string{
+				"a": "a",
+			},
+			Vals: map[string]string{
+				"b": "2",
+			},
+		},
+	}}, true))
+
+	getB := func() (string, bool) {
+		var b string
+		if err := db.Get(&b, "SELECT b FROM tname WHERE a = 'a'"); err != nil {
+			return "", false
+		}
+		return b, true
+	}
+
+	b, ok := getB()
+	assert.True(t, ok)
+	assert.Equal(t, b, "2")
+
+	// revert block 2
+	assert.NoError(t, s.Revert("", "track", 2, []web3.Hash{{0x2}}))
+
+	b, ok = getB()
+	assert.True(t, ok)
+	assert.Equal(t, b, "1")
+
+	// revert block 1, the object does not exist anymore
+	assert.NoError(t, s.Revert("", "track", 1, []web3.Hash{{0x1}}))
+
+	_, ok = getB()
+	assert.False(t, ok)
+}
+
+func TestState_RevertProvider(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	tb := &sdk.Table{
+		Name: "tname",
+		Fields: []*sdk.Field{
+			{Name: "a", Type: sdk.TypeAddress, ID: true},
+			{Name: "b", Type: sdk.TypeUint},
+		},
+	}
+	assert.NoError(t, s.UpsertTable(tb))
+
+	apply := func(track string, num uint64, hash web3.Hash, creation bool, b string) {
+		assert.NoError(t, s.ApplyDiff(&BlockDiff{Provider: "p", Track: track, Number: num, Hash: hash, Diffs: []*protosdk.Diff{
+			{
+				Creation: creation,
+				Table:    "tname",
+				Keys:     map[string]string{"a": "a"},
+				Vals:     map[string]string{"b": b},
+			},
+		}}, true))
+	}
+	getB := func() string {
+		var b string
+		assert.NoError(t, db.Get(&b, "SELECT b FROM tname WHERE a = 'a'"))
+		return b
+	}
+
+	// both tracks of the provider write the same object
+	apply("factory", 1, web3.Hash{0x1}, true, "1")
+	apply("factory", 2, web3.Hash{0x2}, false, "2")
+	apply("pair", 2, web3.Hash{0x2}, false, "3")
+
+	blocks, err := s.GetJournalBlocks("p", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*journalBlock{{Number: 2, Hash: web3.Hash{0x2}.String()}}, blocks)
+
+	// the reorg reverts the diffs of both tracks
+	assert.NoError(t, s.Revert("p", "factory", 2, []web3.Hash{{0x2}}))
+	assert.Equal(t, "1", getB())
+
+	// the other track applies the canonical block and reverts the
+	// stale one afterwards, the canonical diff is kept
+	apply("pair", 2, web3.Hash{0x3}, false, "4")
+	assert.NoError(t, s.Revert("p", "pair", 2, []web3.Hash{{0x2}}))
+	assert.Equal(t, "4", getB())
+}
+
+func TestState_Deletion(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	tb := &sdk.Table{
+		Name: "tname",
+		Fields: []*sdk.Field{
+			{Name: "a", Type: sdk.TypeAddress, ID: true},
+			{Name: "b", Type: sdk.TypeUint},
+			{Name: "c", Type: sdk.TypeString},
+		},
+	}
+	assert.NoError(t, s.UpsertTable(tb))
+
+	// block 1 creates the object
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, Hash: web3.Hash{0x1}, Diffs: []*protosdk.Diff{
+		{
+			Creation: true,
+			Table:    "tname",
+			Keys:     map[string]string{"a": "a"},
+			Vals:     map[string]string{"b": "1"},
+		},
+	}}, true))
+
+	// block 2 deletes the object
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 2, Hash: web3.Hash{0x2}, Diffs: []*protosdk.Diff{
+		{
+			Deletion: true,
+			Table:    "tname",
+			Keys:     map[string]string{"a": "a"},
+		},
+	}}, true))
+
+	getObj := func() (map[string]interface{}, bool) {
+		obj := map[string]interface{}{}
+		if err := db.QueryRowx("SELECT b, c FROM tname WHERE a = 'a'").MapScan(obj); err != nil {
+			return nil, false
+		}
+		return obj, true
+	}
+
+	_, ok := getObj()
+	assert.False(t, ok)
+
+	// revert block 2, the object is inserted again with the same values
+	assert.NoError(t, s.Revert("", "track", 2, []web3.Hash{{0x2}}))
+
+	obj, ok := getObj()
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(obj["b"].([]byte)))
+	assert.Nil(t, obj["c"])
+
+	// the deletion of an object that does not exist is not journaled
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 2, Diffs: []*protosdk.Diff{
+		{
+			Deletion: true,
+			Table:    "tname",
+			Keys:     map[string]string{"a": "b"},
+		},
+	}}, true))
+
+	var entries int
+	assert.NoError(t, db.Get(&entries, "SELECT COUNT(*) FROM journal WHERE block_num = 2"))
+	assert.Equal(t, 0, entries)
 }
 
 func TestState_Track(t *testing.T) {
@@ -145,4 +400,452 @@ func TestState_Track(t *testing.T) {
 	t2, err := s.GetTrackByName("track0")
 	assert.NoError(t, err)
 	assert.Equal(t, t2.LastBlockNum, uint64(1000))
+
+	// applying a diff moves the cursor and creates the new tracks
+	blockDiff := &BlockDiff{
+		Track:  "track0",
+		Number: 1001,
+		Hash:   web3.Hash{0x2},
+		Tracks: []*proto.Track{
+			{
+				Name:     "template-track",
+				FromAddr: web3.Address{0x1}.String(),
+				Template: "template",
+			},
+		},
+	}
+	assert.NoError(t, s.ApplyDiff(blockDiff, true))
+
+	tracks, err := s.GetTracks("")
+	assert.NoError(t, err)
+	assert.Len(t, tracks, 2)
+
+	t3, err := s.GetTrackByName("track0")
+	assert.NoError(t, err)
+	assert.Equal(t, t3.LastBlockNum, uint64(1001))
+	assert.Equal(t, t3.LastBlockHash, web3.Hash{0x2}.String())
+
+	// reverting the block moves the cursor back
+	assert.NoError(t, s.Revert("", "track0", 1001, nil))
+
+	t4, err := s.GetTrackByName("track0")
+	assert.NoError(t, err)
+	assert.Equal(t, t4.LastBlockNum, uint64(1000))
+}
+
+func TestState_Block(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	block := &sdk.Block{
+		Number:     1,
+		Hash:       web3.Hash{0x1},
+		ParentHash: web3.Hash{0x0},
+		Timestamp:  1000,
+	}
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, Block: block}, true))
+
+	found, err := s.GetBlock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, found, block)
+
+	// the block of the canonical chain replaces the previous one
+	block = &sdk.Block{
+		Number:     1,
+		Hash:       web3.Hash{0x2},
+		ParentHash: web3.Hash{0x0},
+		Timestamp:  1001,
+	}
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, Block: block}, true))
+
+	found, err = s.GetBlock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, found, block)
+
+	found, err = s.GetBlock(2)
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestState_Transaction(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	tx := &proto.Transaction{
+		Hash:      web3.Hash{0x1}.String(),
+		BlockHash: web3.Hash{0x2}.String(),
+		BlockNum:  1,
+		FromAddr:  web3.Address{0x3}.String(),
+		ToAddr:    web3.Address{0x4}.String(),
+		Value:     "100",
+		Input:     "0x",
+	}
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, Transactions: []*proto.Transaction{tx}}, true))
+
+	found, err := s.GetTransaction(tx.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, found.FromAddr, tx.FromAddr)
+	assert.Equal(t, found.BlockNum, uint64(1))
+
+	// after a reorg the transaction is included in another block
+	tx.BlockHash = web3.Hash{0x5}.String()
+	tx.BlockNum = 2
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 2, Transactions: []*proto.Transaction{tx}}, true))
+
+	found, err = s.GetTransaction(tx.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, found.BlockNum, uint64(2))
+}
+
+func TestState_Events(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	evnt := func(block, indx uint64) *proto.Event {
+		return &proto.Event{
+			BlockNum:  block,
+			BlockHash: web3.Hash{byte(block)}.String(),
+			LogIndex:  indx,
+			TxHash:    web3.Hash{0x1}.String(),
+			Address:   web3.Address{0x1}.String(),
+		}
+	}
+
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 2, Events: []*proto.Event{evnt(2, 1), evnt(2, 0)}}, true))
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, Events: []*proto.Event{evnt(1, 0)}}, true))
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "other", Number: 1, Events: []*proto.Event{evnt(1, 0)}}, true))
+
+	iterate := func() []*proto.Event {
+		res := []*proto.Event{}
+		err := s.IterateEvents([]string{"track"}, func(track string, evnt *proto.Event) error {
+			assert.Equal(t, track, "track")
+			res = append(res, evnt)
+			return nil
+		})
+		assert.NoError(t, err)
+		return res
+	}
+
+	// the events are sorted by block and log index
+	events := iterate()
+	assert.Len(t, events, 3)
+	assert.Equal(t, events[0].BlockNum, uint64(1))
+	assert.Equal(t, events[1].LogIndex, uint64(0))
+	assert.Equal(t, events[2].LogIndex, uint64(1))
+
+	// the events of the reverted blocks are removed
+	assert.NoError(t, s.Revert("", "track", 2, nil))
+	assert.Len(t, iterate(), 1)
+}
+
+func TestState_DeleteReindex(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	tb := &sdk.Table{
+		Name: "tname",
+		Fields: []*sdk.Field{
+			{Name: "a", Type: sdk.TypeAddress, ID: true},
+		},
+	}
+	assert.NoError(t, s.UpsertTable(tb))
+
+	assert.NoError(t, s.UpsertTrack(&proto.Track{Name: "track", Provider: "p"}))
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Provider: "p", Track: "track", Number: 1, Hash: web3.Hash{0x1}, Diffs: []*protosdk.Diff{
+		{Creation: true, Table: "tname", Keys: map[string]string{"a": "a"}},
+	}}, true))
+
+	store := NewTrackerStore(db)
+	assert.NoError(t, store.Set(lastBlockKey("track"), "block"))
+	entry, err := store.GetEntry("track")
+	assert.NoError(t, err)
+	assert.NoError(t, entry.StoreLogs([]*web3.Log{{BlockNumber: 1, BlockHash: web3.Hash{0x1}}}))
+
+	// the journal and the tracker state of the provider are removed
+	assert.NoError(t, s.DeleteJournal("p"))
+	assert.NoError(t, s.DeleteTrackerLogs("p"))
+
+	blocks, err := s.GetJournalBlocks("p", 0)
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 0)
+
+	index, err := entry.LastIndex()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), index)
+
+	val, err := store.Get(lastBlockKey("track"))
+	assert.NoError(t, err)
+	assert.Empty(t, val)
+}
+
+func TestState_DeadLetters(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	letter := func(block uint64, addr web3.Address, quarantined bool) *proto.DeadLetter {
+		return &proto.DeadLetter{
+			Provider:    "provider",
+			Track:       "track",
+			BlockNum:    block,
+			Address:     addr.String(),
+			ErrorType:   "ErrorPanic",
+			Quarantined: quarantined,
+		}
+	}
+
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, DeadLetters: []*proto.DeadLetter{letter(1, web3.Address{0x1}, false)}}, true))
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 2, DeadLetters: []*proto.DeadLetter{letter(2, web3.Address{0x2}, true)}}, true))
+
+	letters, err := s.GetDeadLetters("provider")
+	assert.NoError(t, err)
+	assert.Len(t, letters, 2)
+
+	quarantined, err := s.GetQuarantined("provider")
+	assert.NoError(t, err)
+	assert.Equal(t, []web3.Address{{0x2}}, quarantined)
+
+	// the dead letters of the reverted blocks are removed
+	assert.NoError(t, s.Revert("provider", "track", 2, nil))
+
+	quarantined, err = s.GetQuarantined("provider")
+	assert.NoError(t, err)
+	assert.Len(t, quarantined, 0)
+
+	assert.NoError(t, s.DeleteDeadLetters("provider"))
+	letters, err = s.GetDeadLetters("provider")
+	assert.NoError(t, err)
+	assert.Len(t, letters, 0)
+}
+
+func TestState_FieldTypes(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	tb := &sdk.Table{
+		Name: "types",
+		Fields: []*sdk.Field{
+			{Name: "id", Type: sdk.TypeString, ID: true},
+			{Name: "b", Type: sdk.TypeBool},
+			{Name: "c", Type: sdk.TypeBytes},
+			{Name: "d", Type: sdk.TypeHash},
+			{Name: "e", Type: sdk.TypeInt},
+			{Name: "f", Type: sdk.TypeTimestamp},
+			{Name: "g", Type: sdk.TypeBigInt},
+		},
+	}
+	assert.NoError(t, s.UpsertTable(tb))
+	s.i = &Server{schemas: map[string]*sdk.Table{"types": tb}}
+
+	vals := []interface{}{
+		"it's a name",
+		true,
+		[]byte{0x1, 0x2},
+		web3.Hash{0x1},
+		int64(-10),
+		time.Unix(1600000000, 0),
+		new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 200)),
+	}
+	data := map[string]string{}
+	for i, f := range tb.Fields {
+		val, err := f.Encode(vals[i])
+		assert.NoError(t, err)
+		data[f.Name] = val
+	}
+
+	keys := map[string]string{"id": data["id"]}
+	delete(data, "id")
+
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, Diffs: []*protosdk.Diff{
+		{Creation: true, Table: "types", Keys: keys, Vals: data},
+	}}, true))
+
+	obj, err := s.GetObj("types", "id", keys["id"])
+	assert.NoError(t, err)
+	for i, f := range tb.Fields {
+		val, err := f.Decode(obj.Data[f.Name])
+		assert.NoError(t, err)
+		if f.Type == sdk.TypeTimestamp {
+			assert.True(t, vals[i].(time.Time).Equal(val.(time.Time)))
+		} else {
+			assert.Equal(t, vals[i], val)
+		}
+	}
+
+	// update the values and revert them with the journal
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 2, Hash: web3.Hash{0x2}, Diffs: []*protosdk.Diff{
+		{Table: "types", Keys: keys, Vals: map[string]string{"b": "false", "c": "\\x03", "f": "2021-01-01T00:00:00Z"}},
+	}}, true))
+	assert.NoError(t, s.Revert("", "track", 2, []web3.Hash{{0x2}}))
+
+	reverted, err := s.GetObj("types", "id", keys["id"])
+	assert.NoError(t, err)
+	assert.Equal(t, obj.Data, reverted.Data)
+}
+
+func TestState_References(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	pair := &sdk.Table{
+		Name: "pair",
+		Fields: []*sdk.Field{
+			{Name: "address", Type: sdk.TypeAddress, ID: true},
+		},
+	}
+	swap := &sdk.Table{
+		Name: "swap",
+		Fields: []*sdk.Field{
+			{Name: "id", Type: sdk.TypeString, ID: true},
+			{Name: "pair", Type: sdk.TypeAddress, References: &sdk.Reference{Table: "pair", Field: "address"}},
+		},
+	}
+	assert.NoError(t, s.UpsertTable(pair))
+	assert.NoError(t, s.UpsertTable(swap))
+
+	// the references can be upserted more than once
+	assert.NoError(t, s.UpsertReferences(swap))
+	assert.NoError(t, s.UpsertReferences(swap))
+
+	swapDiff := func(id string) *protosdk.Diff {
+		return &protosdk.Diff{Creation: true, Table: "swap", Keys: map[string]string{"id": id}, Vals: map[string]string{"pair": "0x1"}}
+	}
+	pairDiff := &protosdk.Diff{Creation: true, Table: "pair", Keys: map[string]string{"address": "0x1"}, Vals: map[string]string{}}
+
+	// the referenced object does not exist
+	assert.Error(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, Diffs: []*protosdk.Diff{swapDiff("a")}}, true))
+
+	// the referenced object is created before
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, Diffs: []*protosdk.Diff{pairDiff, swapDiff("a")}}, true))
+
+	// a deferred constraint is checked at the end of the block
+	_, err = db.Exec("DROP TABLE swap")
+	assert.NoError(t, err)
+
+	swap.Fields[1].References.Deferred = true
+	assert.NoError(t, s.UpsertTable(swap))
+	assert.NoError(t, s.UpsertReferences(swap))
+
+	pairDiff.Keys["address"] = "0x2"
+	diffs := []*protosdk.Diff{swapDiff("b"), pairDiff}
+	diffs[0].Vals["pair"] = "0x2"
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 2, Diffs: diffs}, true))
+}
+
+func TestState_SchemaMigration(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	tb := &sdk.Table{
+		Name: "token",
+		Fields: []*sdk.Field{
+			{Name: "address", Type: sdk.TypeAddress, ID: true},
+			{Name: "numPairs", Type: sdk.TypeInt},
+		},
+	}
+	assert.NoError(t, s.UpsertTable(tb))
+	s.i = &Server{schemas: map[string]*sdk.Table{"token": tb}}
+
+	assert.NoError(t, s.ApplyDiff(&BlockDiff{Track: "track", Number: 1, Diffs: []*protosdk.Diff{
+		{Creation: true, Table: "token", Keys: map[string]string{"address": "0x1"}, Vals: map[string]string{"numPairs": "1"}},
+	}}, true))
+
+	version, err := s.GetSchemaVersion("token")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+
+	// add a field with a default value and widen the integer
+	tb.Fields = []*sdk.Field{
+		{Name: "name", Type: sdk.TypeString, Default: "none"},
+		{Name: "address", Type: sdk.TypeAddress, ID: true},
+		{Name: "numPairs", Type: sdk.TypeUint},
+	}
+	m, err := s.PlanTable(tb)
+	assert.NoError(t, err)
+	assert.Len(t, m.DDL, 2)
+	assert.NoError(t, s.MigrateTable(m))
+
+	obj, err := s.GetObj("token", "address", "0x1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"address": "0x1", "numpairs": "1", "name": "none"}, obj.Data)
+
+	version, err = s.GetSchemaVersion("token")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), version)
+
+	// nothing to migrate
+	m, err = s.PlanTable(tb)
+	assert.NoError(t, err)
+	assert.Empty(t, m.DDL)
+	assert.Equal(t, uint64(2), m.Version)
+
+	// the ID fields cannot be removed
+	tb.Fields = tb.Fields[:1]
+	tb.Fields[0].ID = true
+	_, err = s.PlanTable(tb)
+	assert.Error(t, err)
+}
+
+func TestState_Indexes(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	s, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	tb := &sdk.Table{
+		Name: "swap",
+		Fields: []*sdk.Field{
+			{Name: "id", Type: sdk.TypeString, ID: true},
+			{Name: "pair", Type: sdk.TypeAddress},
+			{Name: "amount", Type: sdk.TypeInt},
+		},
+		Indexes: []*sdk.Index{
+			{Name: "swap_pair_idx", Fields: []sdk.IndexField{sdk.Asc("pair"), sdk.Desc("amount")}, Where: "amount > 0"},
+		},
+	}
+	assert.NoError(t, s.UpsertTable(tb))
+
+	// the indexes are reconciled
+	m, err := s.PlanTable(tb)
+	assert.NoError(t, err)
+	assert.Empty(t, m.Indexes)
+
+	tb.Indexes[0].Where = ""
+	assert.NoError(t, s.UpsertTable(tb))
+
+	var def string
+	assert.NoError(t, db.Get(&def, "SELECT indexdef FROM pg_indexes WHERE indexname = 'swap_pair_idx'"))
+	assert.Equal(t, "CREATE INDEX swap_pair_idx ON public.swap USING btree (pair, amount DESC)", def)
+
+	// the removed indexes are dropped
+	tb.Indexes = nil
+	assert.NoError(t, s.UpsertTable(tb))
+
+	var count int
+	assert.NoError(t, db.Get(&count, "SELECT count(*) FROM pg_indexes WHERE indexname = 'swap_pair_idx'"))
+	assert.Equal(t, 0, count)
 }
@@ -0,0 +1,117 @@
+package indexer
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/eth-indexer/sdk"
+	"github.com/umbracle/go-web3"
+)
+
+// Status is the status of the indexer
+type Status struct {
+	// Head is the last head of the chain
+	Head uint64 `json:"head"`
+
+	Providers []*ProviderStatus `json:"providers"`
+}
+
+// ProviderStatus is the status of a provider running in the indexer
+type ProviderStatus struct {
+	Name   string            `json:"name"`
+	Policy sdk.FailurePolicy `json:"policy"`
+
+	// Err is the error that stopped the provider (if any)
+	Err string `json:"err,omitempty"`
+
+	// Failures is the number of errors of the handlers
+	// and LastFailure the last one of them
+	Failures    uint64 `json:"failures"`
+	LastFailure string `json:"lastFailure,omitempty"`
+
+	// Quarantined are the contracts whose events are skipped
+	Quarantined []web3.Address `json:"quarantined"`
+
+	// Tracks is the sync progress of the tracks of the provider
+	Tracks []*TrackStatus `json:"tracks"`
+}
+
+// Status returns the status of the indexer and its providers
+func (s *Server) Status() (*Status, error) {
+	providers, err := s.ProviderStatus()
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{
+		Head:      s.tracker.head.getHead(),
+		Providers: providers,
+	}
+	return status, nil
+}
+
+// ProviderStatus returns the status of the providers sorted by name
+func (s *Server) ProviderStatus() ([]*ProviderStatus, error) {
+	tracks := map[string][]*TrackStatus{}
+	for _, track := range s.tracker.trackStatus() {
+		tracks[track.Provider] = append(tracks[track.Provider], track)
+	}
+
+	res := []*ProviderStatus{}
+	for _, p := range s.providers {
+		status, err := p.status(s.state)
+		if err != nil {
+			return nil, err
+		}
+		status.Tracks = tracks[p.name]
+		res = append(res, status)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// DeadLetters returns the events (or calls) skipped by the provider
+func (s *Server) DeadLetters(provider string) ([]*proto.DeadLetter, error) {
+	return s.state.GetDeadLetters(provider)
+}
+
+// handleStatus serves the status of the indexer as json
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.Status()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		s.logger.Error("failed to encode the status", "err", err)
+	}
+}
+
+func (p *providerSrv) status(state *State) (*ProviderStatus, error) {
+	p.statusLock.Lock()
+	status := &ProviderStatus{
+		Name:     p.name,
+		Policy:   p.policy,
+		Failures: p.failures,
+	}
+	if p.err != nil {
+		status.Err = p.err.Error()
+	}
+	if p.lastFailure != nil {
+		status.LastFailure = p.lastFailure.Error()
+	}
+	p.statusLock.Unlock()
+
+	// the quarantined contracts are read from the state since
+	// the lock of the provider might be held by a long retry
+	quarantined, err := state.GetQuarantined(p.name)
+	if err != nil {
+		return nil, err
+	}
+	status.Quarantined = quarantined
+	return status, nil
+}
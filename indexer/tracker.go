@@ -2,51 +2,124 @@ package indexer
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math/big"
 	"sort"
-	"time"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/go-hclog"
 
 	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/eth-indexer/indexer/rpcpool"
+	"github.com/umbracle/eth-indexer/indexer/tracker"
 	"github.com/umbracle/eth-indexer/sdk"
 	"github.com/umbracle/go-web3"
-	"github.com/umbracle/go-web3/jsonrpc"
-	"github.com/umbracle/go-web3/tracker"
+	gproto "google.golang.org/protobuf/proto"
 )
 
 type trackerSrv struct {
 	logger   hclog.Logger
 	srv      *Server
 	tracker  *tracker.Tracker
-	provider *jsonrpc.Client
+	store    *TrackerStore
+	provider *rpcpool.Pool
+	head     *headTracker
+
+	// tracks is the set of tracks running and progress their sync progress
+	tracks     map[string]struct{}
+	progress   map[string]*trackProgress
+	tracksLock sync.Mutex
+
+	// set if the node does not support the trace endpoints
+	noTraceFilter int32
+	noDebugTrace  int32
 }
 
-var (
-	zeroAddr = web3.Address{}
-)
+// providerSrv is a provider running in the indexer. Each provider has
+// its own tracks and snapshot cache and, if it fails, it stops without
+// affecting the other providers.
+type providerSrv struct {
+	name     string
+	logger   hclog.Logger
+	provider *sdk.Provider
 
-func (t *trackerSrv) setupTracker(indexer *sdk.Provider) error {
-	provider, err := jsonrpc.NewClient(t.srv.config.JSONRPCEndpoint)
-	if err != nil {
-		return err
+	// lock serializes the processing of the tracks of
+	// the provider since they share the same snapshot
+	lock sync.Mutex
+
+	// policy is how the errors of the handlers are handled
+	policy sdk.FailurePolicy
+
+	// quarantined are the contracts whose events and calls are skipped
+	quarantined map[web3.Address]struct{}
+
+	// halt stops the indexer process
+	halt func(err error)
+
+	// statusLock protects the status of the provider since
+	// the lock is held while the blocks are processed
+	statusLock sync.Mutex
+
+	// reverts counts the reverts of the provider, the blocks retried
+	// without the lock are checked again if it changes meanwhile
+	reverts uint64
+
+	// err is the error that stopped the provider
+	err error
+
+	// failures is the number of errors of the handlers
+	failures    uint64
+	lastFailure *sdk.ErrorEvent
+}
+
+// fail stops the provider. It must be called with the lock held.
+func (p *providerSrv) fail(err error) {
+	if p.err != nil {
+		return
 	}
-	t.provider = provider
+	p.statusLock.Lock()
+	p.err = err
+	p.statusLock.Unlock()
+
+	p.logger.Error("provider stopped", "policy", p.policy, "err", err)
+	if p.policy == sdk.FailureHalt && p.halt != nil {
+		p.halt(fmt.Errorf("provider '%s' failed: %v", p.name, err))
+	}
+}
+
+func (t *trackerSrv) setupTracker() error {
+	t.provider = t.srv.client
 
 	t.logger.Info("start tracker", "batch", t.srv.config.BatchSize)
 
 	tConfig := tracker.DefaultConfig()
 	tConfig.BatchSize = t.srv.config.BatchSize
 	tConfig.EtherscanFastTrack = true
+	tConfig.MaxBlockBacklog = t.srv.config.MaxReorgDepth
 
-	// use an sql store for the tracker
-	store, err := New("./test.db")
-	if err != nil {
-		return err
+	// follow the head with a newHeads subscription if available
+	pollInterval := t.srv.config.HeadPollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultHeadPollInterval
+	}
+	t.head = &headTracker{
+		logger:       t.logger.Named("head"),
+		provider:     t.provider.Eth(),
+		subscribe:    t.provider.Subscribe,
+		pollInterval: pollInterval,
 	}
+	tConfig.BlockTracker = t.head
 
-	t.tracker = tracker.NewTracker(provider.Eth(), tConfig)
-	t.tracker.SetStore(store)
+	// use the state database as the store for the tracker
+	t.store = NewTrackerStore(t.srv.state.db)
+	t.tracker = tracker.NewTracker(t.provider.Eth(), tConfig)
+	t.tracker.SetStore(t.store)
+
+	// the journal has to cover the deepest reorg the tracker reconciles
+	t.srv.state.journalDepth = tConfig.MaxBlockBacklog
 
 	go func() {
 		if err := t.tracker.Start(context.Background()); err != nil {
@@ -57,27 +130,135 @@ func (t *trackerSrv) setupTracker(indexer *sdk.Provider) error {
 	// wait for the tracker to be ready
 	<-t.tracker.ReadyCh
 
-	filter := indexer.GetFilter()
-	track := &proto.Track{
-		Name:       "-",
-		StartBlock: filter.StartBlock,
-	}
-	if filter.FromAddr != zeroAddr {
-		track.FromAddr = filter.FromAddr.String()
+	go t.logProgress()
+	return nil
+}
+
+// startProvider starts the tracks of the provider
+func (t *trackerSrv) startProvider(p *providerSrv) error {
+	// create one track for each source of the filter
+	for _, source := range p.provider.GetSources() {
+		track := trackFromSource(p.name, source)
+		if err := t.srv.state.UpsertTrack(track); err != nil {
+			return err
+		}
+		t.checkTrack(track)
+
+		if len(p.provider.CallTrackers) != 0 {
+			// index the calls to the contracts of the source
+			if callTrack := callTrackFromSource(p.name, source); callTrack != nil {
+				if err := t.srv.state.UpsertTrack(callTrack); err != nil {
+					return err
+				}
+			}
+		}
 	}
-	if filter.ToAddr != zeroAddr {
-		track.ToAddr = filter.ToAddr.String()
+
+	if len(p.provider.BlockTrackers) != 0 {
+		// run the block trackers
+		if err := t.srv.state.UpsertTrack(blockTrack(p.name, p.provider)); err != nil {
+			return err
+		}
 	}
 
-	if err := t.srv.state.UpsertTrack(track); err != nil {
+	// start all the tracks, this includes the dynamic data sources
+	// created by the templates in previous runs
+	tracks, err := t.srv.state.GetTracks(p.name)
+	if err != nil {
 		return err
 	}
-	if err := t.startTrack(track, indexer); err != nil {
-		return err
+	for _, track := range tracks {
+		if err := t.startTrack(track, p); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// trackFromSource builds the track for a source of the provider filter
+func trackFromSource(provider string, source *sdk.Source) *proto.Track {
+	topics := []string{}
+	for _, topic := range source.Topics {
+		topics = append(topics, topic.String())
+	}
+	track := &proto.Track{
+		Name:       provider + "." + source.Name,
+		Provider:   provider,
+		FromAddr:   joinAddrs(source.Address),
+		ToAddr:     joinAddrs(source.To),
+		Topic:      strings.Join(topics, ","),
+		StartBlock: source.StartBlock,
+	}
+	return track
+}
+
+// checkTrack warns if the filter of the stored track is different from the one
+// of the source since the track is not reindexed with the new filter
+func (t *trackerSrv) checkTrack(track *proto.Track) {
+	stored, err := t.srv.state.GetTrackByName(track.Name)
+	if err != nil || stored == nil {
+		return
+	}
+	if stored.FromAddr != track.FromAddr || stored.ToAddr != track.ToAddr || stored.Topic != track.Topic || stored.StartBlock != track.StartBlock {
+		t.logger.Warn("the filter of the source changed, rename the source to index it again", "name", track.Name)
+	}
+}
+
+func joinAddrs(addrs []web3.Address) string {
+	res := []string{}
+	for _, addr := range addrs {
+		res = append(res, addr.String())
+	}
+	return strings.Join(res, ",")
+}
+
+func splitList(str string) []string {
+	if str == "" {
+		return nil
+	}
+	return strings.Split(str, ",")
+}
+
+func parseAddrs(str string) ([]web3.Address, error) {
+	res := []web3.Address{}
+	for _, item := range splitList(str) {
+		var addr web3.Address
+		if err := addr.UnmarshalText([]byte(item)); err != nil {
+			return nil, err
+		}
+		res = append(res, addr)
+	}
+	return res, nil
+}
+
+func parseTopics(str string) ([]web3.Hash, error) {
+	res := []web3.Hash{}
+	for _, item := range splitList(str) {
+		var hash web3.Hash
+		if err := hash.UnmarshalText([]byte(item)); err != nil {
+			return nil, err
+		}
+		res = append(res, hash)
+	}
+	return res, nil
+}
+
+// trackFromDataSource builds the track for a data source created by a template
+func trackFromDataSource(provider string, source *sdk.DataSource) *proto.Track {
+	track := &proto.Track{
+		Name:     provider + "." + source.Template + "-" + source.Address.String(),
+		Provider: provider,
+		FromAddr: source.Address.String(),
+		Template: source.Template,
+	}
+	// the filter starts after the start block but the contract
+	// might emit events in the same block it was created
+	if source.StartBlock != 0 {
+		track.StartBlock = source.StartBlock - 1
+	}
+	return track
+}
+
 func filterConfigFromTracker(t *proto.Track) (*tracker.FilterConfig, error) {
 	config := &tracker.FilterConfig{
 		Async:   false,
@@ -88,35 +269,51 @@ func filterConfigFromTracker(t *proto.Track) (*tracker.FilterConfig, error) {
 		Topics:  []*web3.Hash{},
 	}
 
-	if t.ToAddr != "" {
-		var addr web3.Address
-		if err := addr.UnmarshalText([]byte(t.ToAddr)); err != nil {
-			return nil, err
-		}
-		config.To = []web3.Address{addr}
+	var err error
+	if config.To, err = parseAddrs(t.ToAddr); err != nil {
+		return nil, err
 	}
-	if t.FromAddr != "" {
-		var addr web3.Address
-		if err := addr.UnmarshalText([]byte(t.FromAddr)); err != nil {
-			return nil, err
-		}
-		config.Address = []web3.Address{addr}
+	if config.Address, err = parseAddrs(t.FromAddr); err != nil {
+		return nil, err
 	}
-	if t.Topic != "" {
-		var hash web3.Hash
-		if err := hash.UnmarshalText([]byte(t.Topic)); err != nil {
-			return nil, err
-		}
-		config.Topics = append(config.Topics, &hash)
+	topics, err := parseTopics(t.Topic)
+	if err != nil {
+		return nil, err
+	}
+	if len(topics) == 1 {
+		// the topics of the log filter are positional, more than one
+		// topic is filtered once the logs are received (see filterLogs)
+		config.Topics = append(config.Topics, &topics[0])
 	}
 	return config, nil
 }
 
+// filterLogs returns the logs that match any of the topics
+func filterLogs(logs []*web3.Log, topics []web3.Hash) []*web3.Log {
+	if len(topics) == 0 {
+		return logs
+	}
+	res := []*web3.Log{}
+	for _, log := range logs {
+		if len(log.Topics) == 0 {
+			continue
+		}
+		for _, topic := range topics {
+			if log.Topics[0] == topic {
+				res = append(res, log)
+				break
+			}
+		}
+	}
+	return res
+}
+
 func processEvents(logs []*web3.Log) []*sdk.Action {
 	res := []*sdk.Action{}
 
 	act := &sdk.Action{
-		BlockNum: logs[0].BlockNumber,
+		BlockNum:  logs[0].BlockNumber,
+		BlockHash: logs[0].BlockHash,
 	}
 
 	for _, log := range logs {
@@ -124,7 +321,8 @@ func processEvents(logs []*web3.Log) []*sdk.Action {
 			sort.Sort(act.Events)
 			res = append(res, act)
 			act = &sdk.Action{
-				BlockNum: log.BlockNumber,
+				BlockNum:  log.BlockNumber,
+				BlockHash: log.BlockHash,
 			}
 		}
 		act.Events = append(act.Events, *proto.DecodeEvent(log))
@@ -135,11 +333,88 @@ func processEvents(logs []*web3.Log) []*sdk.Action {
 	return res
 }
 
-func (t *trackerSrv) startTrack(track *proto.Track, indexer *sdk.Provider) error {
+// lastBlockKey is the key used by the go-web3 tracker to store the last block of a filter
+func lastBlockKey(hash string) string {
+	return "lastBlock_" + hash
+}
+
+// resetFilterCursor moves the last block of the tracker filter to the cursor of the track
+// since the diffs and the cursor are committed together and the tracker store might be
+// ahead (i.e. crashed before the diffs were applied).
+func (t *trackerSrv) resetFilterCursor(track *proto.Track, fConfig *tracker.FilterConfig) error {
+	// remove the logs that the tracker stored ahead of the cursor
+	if err := t.store.RemoveLogsAfter(fConfig.Hash, track.LastBlockNum); err != nil {
+		return err
+	}
+
+	key := lastBlockKey(fConfig.Hash)
+	if track.LastBlockNum == 0 {
+		// nothing processed yet, start from the beginning of the filter
+		return t.store.Set(key, "")
+	}
+
+	var block *web3.Block
+	if track.LastBlockHash != "" {
+		// use the block by hash so that the tracker can detect a reorg
+		// that happened while the indexer was not running
+		var hash web3.Hash
+		if err := hash.UnmarshalText([]byte(track.LastBlockHash)); err != nil {
+			return err
+		}
+		if b, err := t.provider.Eth().GetBlockByHash(hash, false); err == nil && b != nil {
+			block = b
+		} else {
+			t.logger.Warn("cursor block not found by hash", "track", track.Name, "hash", track.LastBlockHash)
+		}
+	}
+	if block == nil {
+		b, err := t.provider.Eth().GetBlockByNumber(web3.BlockNumber(track.LastBlockNum), false)
+		if err != nil {
+			return err
+		}
+		block = b
+	}
+	if block.Difficulty == nil {
+		block.Difficulty = big.NewInt(0)
+	}
+	buf, err := block.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return t.store.Set(key, hex.EncodeToString(buf))
+}
+
+func (t *trackerSrv) startTrack(track *proto.Track, p *providerSrv) error {
+	t.tracksLock.Lock()
+	if _, ok := t.tracks[track.Name]; ok {
+		t.tracksLock.Unlock()
+		return nil
+	}
+	t.tracks[track.Name] = struct{}{}
+	t.tracksLock.Unlock()
+
+	t.startProgress(track.Name, p.name, track.StartBlock, track.LastBlockNum)
+
+	if track.Calls {
+		return t.startCallTrack(track, p)
+	}
+	if track.Blocks {
+		return t.startBlockTrack(track, p)
+	}
+
 	fConfig, err := filterConfigFromTracker(track)
 	if err != nil {
 		return err
 	}
+	topics, err := parseTopics(track.Topic)
+	if err != nil {
+		return err
+	}
+
+	// resume from the cursor of the track
+	if err := t.resetFilterCursor(track, fConfig); err != nil {
+		return err
+	}
 
 	filter, err := t.tracker.NewFilter(fConfig)
 	if err != nil {
@@ -154,43 +429,314 @@ func (t *trackerSrv) startTrack(track *proto.Track, indexer *sdk.Provider) error
 		t.logger.Debug("last block", "block", lastBlock.Number)
 	}
 
+	go t.trackFilter(track, p, filter, topics)
+
 	go func() {
-		for {
-			select {
-			case num := <-filter.SyncCh:
-				fmt.Printf("--- %s %s num %d\n", track.Name, time.Now(), num)
+		if err := filter.Sync(context.Background()); err != nil {
+			t.logger.Error("failed to sync", "err", err)
+		}
+	}()
 
-			case evnt := <-filter.EventCh:
-				if len(evnt.Added) == 0 {
-					continue
-				}
+	t.logger.Debug("start track")
+	return nil
+}
+
+// trackFilter processes the events of the filter of the track. If the provider
+// fails, the events are still received and dropped since the filters share the
+// tracker and a blocked filter would stop the tracks of the other providers.
+func (t *trackerSrv) trackFilter(track *proto.Track, p *providerSrv, filter *tracker.Filter, topics []web3.Hash) {
+	var failed bool
+	for {
+		select {
+		case num := <-filter.SyncCh:
+			// the filter synced up to the block, including the blocks without logs
+			t.logger.Debug("track synced", "track", track.Name, "block", num)
+			if !failed {
+				t.getProgress(track.Name).setBlock(num)
+			}
 
-				actions := processEvents(evnt.Added)
-				for _, act := range actions {
-					diffs, err := indexer.Process(act)
-					if err != nil {
-						fmt.Printf("Failed to process: %v", err)
-						return
-					} else {
-						if err := t.srv.state.ApplyDiff(diffs, true); err != nil {
-							fmt.Printf("Failed to apply diff: %v", err)
-							return
-						}
-					}
+		case evnt := <-filter.EventCh:
+			if failed {
+				continue
+			}
+			evnt.Added = filterLogs(evnt.Added, topics)
+			evnt.Removed = filterLogs(evnt.Removed, topics)
+
+			if len(evnt.Removed) != 0 {
+				// there was a reorg, revert the blocks that are not part
+				// of the canonical chain anymore
+				if err := t.revertTrack(track, evnt.Removed, p); err != nil {
+					// the provider failed, drop the next events of the track
+					failed = true
+					continue
 				}
+			}
+			if len(evnt.Added) == 0 {
+				continue
+			}
 
-			case <-filter.DoneCh:
-				fmt.Printf("-- evnt done %s\n", track.Name)
+			actions := processEvents(evnt.Added)
+			if err := t.processActions(track, actions, p); errors.Is(err, errReverted) {
+				// the blocks are not canonical anymore, the tracker
+				// removes their logs and sends the ones of the new chain
+				continue
+			} else if err != nil {
+				// the provider failed, drop the next events of the track
+				failed = true
 			}
+
+		case <-filter.DoneCh:
+			t.logger.Debug("track done", "track", track.Name)
 		}
-	}()
+	}
+}
 
-	go func() {
-		if err := filter.Sync(context.Background()); err != nil {
-			t.logger.Error("failed to sync", "err", err)
+// processActions processes the actions of the track and applies the diffs
+func (t *trackerSrv) processActions(track *proto.Track, actions []*sdk.Action, p *providerSrv) (err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+	defer func() {
+		if err != nil && !errors.Is(err, errReverted) {
+			p.fail(err)
 		}
 	}()
 
-	t.logger.Debug("start track")
+	for _, act := range actions {
+		if act.BlockNum <= track.LastBlockNum {
+			// the block was already applied
+			continue
+		}
+		act.Template = track.Template
+
+		block, err := t.getBlock(act)
+		if err != nil {
+			return err
+		}
+		act.Block = block
+		act.BlockHash = block.Hash
+
+		blockDiff := &BlockDiff{
+			Provider: p.name,
+			Track:    track.Name,
+			Number:   act.BlockNum,
+			Hash:     act.BlockHash,
+			Block:    block,
+		}
+		// archive all the logs, including the ones skipped by the failure policy
+		for i := range act.Events {
+			blockDiff.Events = append(blockDiff.Events, gproto.Clone(&act.Events[i]).(*proto.Event))
+		}
+
+		if err := t.applyAction(track, act, p, blockDiff); err != nil {
+			return err
+		}
+		updateHeadLag(track.Name, t.head.getHead(), block)
+		t.getProgress(track.Name).add(act.BlockNum, len(blockDiff.Events)+len(act.Calls), len(blockDiff.Diffs))
+
+		track.LastBlockNum = act.BlockNum
+		track.LastBlockHash = act.BlockHash.String()
+
+		// start the new data sources
+		for _, newTrack := range blockDiff.Tracks {
+			if err := t.startTrack(newTrack, p); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
+
+// applyAction processes the action and applies the diff of the block. If another
+// track reverts the provider while the block is retried without the lock, the
+// block is processed again as long as it is still part of the canonical chain,
+// otherwise, errReverted is returned and the tracker delivers the new chain.
+func (t *trackerSrv) applyAction(track *proto.Track, act *sdk.Action, p *providerSrv, blockDiff *BlockDiff) error {
+	apply := func() (err error) {
+		if blockDiff.Diffs, err = p.process(track.Name, act, blockDiff); err != nil {
+			return err
+		}
+		if p.provider.StoreTransactions {
+			if blockDiff.Transactions, err = t.getTransactions(act, p.provider.Transactions()); err != nil {
+				return err
+			}
+		}
+		for _, source := range p.provider.DataSources() {
+			blockDiff.Tracks = append(blockDiff.Tracks, trackFromDataSource(p.name, source))
+		}
+		return p.applyDiff(t.srv.state, blockDiff)
+	}
+
+	for {
+		err := apply()
+		if !errors.Is(err, errReverted) {
+			return err
+		}
+		canonical, err := t.isCanonical(act)
+		if err != nil {
+			return err
+		}
+		if !canonical {
+			p.logger.Warn("block reverted while retrying", "track", track.Name, "block", act.BlockNum)
+			return errReverted
+		}
+		// the diffs were computed with the state before the revert
+		p.logger.Warn("provider reverted while retrying, process the block again", "track", track.Name, "block", act.BlockNum)
+		blockDiff.Diffs = nil
+		blockDiff.Transactions = nil
+		blockDiff.Tracks = nil
+	}
+}
+
+// isCanonical returns whether the block of the action is part of the canonical chain
+func (t *trackerSrv) isCanonical(act *sdk.Action) (bool, error) {
+	block, err := t.provider.Eth().GetBlockByNumber(web3.BlockNumber(act.BlockNum), false)
+	if err != nil {
+		return false, err
+	}
+	return block != nil && block.Hash == act.BlockHash, nil
+}
+
+// getBlock returns the header of the block that includes the events of the action
+func (t *trackerSrv) getBlock(act *sdk.Action) (*sdk.Block, error) {
+	var block *web3.Block
+	var err error
+	if act.BlockHash != (web3.Hash{}) {
+		block, err = t.provider.Eth().GetBlockByHash(act.BlockHash, false)
+	} else {
+		block, err = t.provider.Eth().GetBlockByNumber(web3.BlockNumber(act.BlockNum), false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", act.BlockNum)
+	}
+	res := &sdk.Block{
+		Number:     block.Number,
+		Hash:       block.Hash,
+		ParentHash: block.ParentHash,
+		Timestamp:  block.Timestamp,
+	}
+	return res, nil
+}
+
+// getTransactions returns the transactions of the events and calls of the action. The
+// transactions already queried by the handlers are not queried again.
+func (t *trackerSrv) getTransactions(act *sdk.Action, queried []*web3.Transaction) ([]*proto.Transaction, error) {
+	txns := map[web3.Hash]*web3.Transaction{}
+	for _, txn := range queried {
+		txns[txn.Hash] = txn
+	}
+
+	hashes := []web3.Hash{}
+	for i := range act.Events {
+		var hash web3.Hash
+		if err := hash.UnmarshalText([]byte(act.Events[i].TxHash)); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	for _, call := range act.Calls {
+		hashes = append(hashes, call.TxHash)
+	}
+
+	res := []*proto.Transaction{}
+	for _, hash := range hashes {
+		txn, ok := txns[hash]
+		if ok && txn == nil {
+			// already included
+			continue
+		}
+		if !ok {
+			var err error
+			if txn, err = t.provider.Eth().GetTransactionByHash(hash); err != nil {
+				return nil, err
+			}
+			if txn == nil {
+				return nil, fmt.Errorf("transaction %s not found", hash)
+			}
+		}
+		res = append(res, proto.DecodeTransaction(txn))
+		txns[hash] = nil
+	}
+	return res, nil
+}
+
+// revertTrack reverts the state changes of the blocks that included the removed logs
+func (t *trackerSrv) revertTrack(track *proto.Track, removed []*web3.Log, p *providerSrv) (err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+	defer func() {
+		if err != nil {
+			p.fail(err)
+		}
+	}()
+
+	fromBlock := removed[0].BlockNumber
+	for _, log := range removed {
+		if log.BlockNumber < fromBlock {
+			fromBlock = log.BlockNumber
+		}
+	}
+
+	stale, err := t.staleBlocks(p.name, fromBlock)
+	if err != nil {
+		return err
+	}
+
+	p.logger.Info("revert track", "name", track.Name, "block", fromBlock, "logs", len(removed), "stale", len(stale))
+
+	if err := t.srv.state.Revert(p.name, track.Name, fromBlock, stale); err != nil {
+		return err
+	}
+	p.reverts++
+	if track.LastBlockNum >= fromBlock {
+		track.LastBlockNum = fromBlock - 1
+		track.LastBlockHash = ""
+	}
+
+	// the cached objects might include values from the reverted blocks
+	p.provider.Invalidate()
+
+	// the contracts quarantined in the reverted blocks are not anymore
+	return p.loadQuarantined(t.srv.state)
+}
+
+// staleBlocks returns the blocks at or after 'fromBlock' with diffs of the provider
+// that are not part of the canonical chain anymore. The diffs of the other tracks
+// in those blocks are reverted as well and, if they were already reverted by
+// another track, the diffs of the canonical blocks applied since are kept.
+func (t *trackerSrv) staleBlocks(provider string, fromBlock uint64) ([]web3.Hash, error) {
+	blocks, err := t.srv.state.GetJournalBlocks(provider, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	canonical := map[uint64]web3.Hash{}
+
+	stale := []web3.Hash{}
+	for _, block := range blocks {
+		hash, ok := canonical[block.Number]
+		if !ok {
+			b, err := t.provider.Eth().GetBlockByNumber(web3.BlockNumber(block.Number), false)
+			if err != nil {
+				return nil, err
+			}
+			if b != nil {
+				hash = b.Hash
+			}
+			canonical[block.Number] = hash
+		}
+		if hash.String() != block.Hash {
+			stale = append(stale, web3.HexToHash(block.Hash))
+		}
+	}
+	return stale, nil
+}
@@ -0,0 +1,7 @@
+// Package tracker is the contract event tracker of go-web3
+// (github.com/umbracle/go-web3/tracker at the version in go.mod) with the
+// block tracker as an option of the config so that the indexer follows the
+// head of the chain with its own block tracker. The store interface is
+// still the one of go-web3. It is distributed under the MPL-2.0 license
+// of go-web3 (see LICENSE).
+package tracker
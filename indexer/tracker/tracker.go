@@ -177,6 +177,10 @@ type Config struct {
 	MaxBlockBacklog    uint64
 	EtherscanFastTrack bool
 	EtherscanAPIKey    string
+
+	// BlockTracker tracks the new blocks of the chain
+	// (optional, it polls the provider by default)
+	BlockTracker BlockTracker
 }
 
 // DefaultConfig returns the default tracker config
@@ -226,13 +230,14 @@ func NewTracker(provider Provider, config *Config) *Tracker {
 		config.MaxBlockBacklog = defaultMaxBlockBacklog
 	}
 	return &Tracker{
-		provider: provider,
-		config:   config,
-		blocks:   []*web3.Block{},
-		filters:  []*Filter{},
-		BlockCh:  make(chan *BlockEvent, 1),
-		logger:   log.New(ioutil.Discard, "", log.LstdFlags),
-		ReadyCh:  make(chan struct{}),
+		provider:     provider,
+		config:       config,
+		blocks:       []*web3.Block{},
+		filters:      []*Filter{},
+		blockTracker: config.BlockTracker,
+		BlockCh:      make(chan *BlockEvent, 1),
+		logger:       log.New(ioutil.Discard, "", log.LstdFlags),
+		ReadyCh:      make(chan struct{}),
 	}
 }
 
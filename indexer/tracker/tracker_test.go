@@ -0,0 +1,67 @@
+package tracker
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	web3 "github.com/umbracle/go-web3"
+	"github.com/umbracle/go-web3/tracker/store"
+)
+
+type mockProvider struct {
+	Provider
+}
+
+func (m *mockProvider) GetBlockByNumber(i web3.BlockNumber, full bool) (*web3.Block, error) {
+	// the chain only has the genesis
+	return &web3.Block{Number: 0, Hash: web3.Hash{0x1}}, nil
+}
+
+func (m *mockProvider) ChainID() (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+type mockStore struct {
+	store.Store
+	kv map[string]string
+}
+
+func (m *mockStore) Get(k string) (string, error) {
+	return m.kv[k], nil
+}
+
+func (m *mockStore) Set(k, v string) error {
+	m.kv[k] = v
+	return nil
+}
+
+type mockBlockTracker struct {
+	trackCh chan struct{}
+}
+
+func (m *mockBlockTracker) Track(ctx context.Context, handle func(block *web3.Block) error) error {
+	close(m.trackCh)
+	return nil
+}
+
+func TestTracker_ConfigBlockTracker(t *testing.T) {
+	blockTracker := &mockBlockTracker{trackCh: make(chan struct{})}
+
+	config := DefaultConfig()
+	config.BlockTracker = blockTracker
+
+	tt := NewTracker(&mockProvider{}, config)
+	tt.SetStore(&mockStore{kv: map[string]string{}})
+
+	if err := tt.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-blockTracker.trackCh:
+	case <-time.After(time.Second):
+		t.Fatal("the block tracker of the config is not used")
+	}
+}
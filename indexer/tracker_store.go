@@ -1,168 +1,191 @@
 package indexer
 
 import (
-	"bytes"
-	"encoding/binary"
+	"database/sql"
 
-	"github.com/boltdb/bolt"
+	"github.com/jmoiron/sqlx"
+	"github.com/umbracle/eth-indexer/indexer/proto"
 	"github.com/umbracle/go-web3"
 	"github.com/umbracle/go-web3/tracker/store"
 )
 
-var _ store.Store = (*BoltStore)(nil)
+var _ store.Store = (*TrackerStore)(nil)
 
-var (
-	dbLogs = []byte("logs")
-	dbConf = []byte("conf")
-)
-
-// BoltStore is a tracker store implementation.
-type BoltStore struct {
-	conn *bolt.DB
-}
-
-// New creates a new boltdbstore
-func New(path string) (*BoltStore, error) {
-	db, err := bolt.Open(path, 0600, nil)
-	if err != nil {
-		return nil, err
-	}
-	store := &BoltStore{
-		conn: db,
-	}
-	if err := store.setupDB(); err != nil {
-		store.Close()
-		return nil, err
-	}
-	return store, nil
+// TrackerStore is a tracker store implementation on top of the
+// same Postgresql database used by the state.
+type TrackerStore struct {
+	db *sqlx.DB
 }
 
-func (b *BoltStore) setupDB() error {
-	txn, err := b.conn.Begin(true)
-	if err != nil {
-		return err
+// NewTrackerStore creates a new tracker store. The tables are created
+// by the state migrations.
+func NewTrackerStore(db *sqlx.DB) *TrackerStore {
+	return &TrackerStore{
+		db: db,
 	}
-	defer txn.Rollback()
-
-	if _, err := txn.CreateBucketIfNotExists(dbConf); err != nil {
-		return err
-	}
-	return txn.Commit()
 }
 
 // Close implements the store interface
-func (b *BoltStore) Close() error {
-	return b.conn.Close()
+func (p *TrackerStore) Close() error {
+	// the database is owned by the state
+	return nil
 }
 
 // Get implements the store interface
-func (b *BoltStore) Get(k string) (string, error) {
-	txn, err := b.conn.Begin(false)
-	if err != nil {
+func (p *TrackerStore) Get(k string) (string, error) {
+	var out string
+	if err := p.db.Get(&out, "SELECT val FROM tracker_kv WHERE key = $1", k); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
 		return "", err
 	}
-	defer txn.Rollback()
-
-	bucket := txn.Bucket(dbConf)
-	val := bucket.Get([]byte(k))
-
-	return string(val), nil
+	return out, nil
 }
 
 // ListPrefix implements the store interface
-func (b *BoltStore) ListPrefix(prefix string) ([]string, error) {
-	txn, err := b.conn.Begin(false)
-	if err != nil {
+func (p *TrackerStore) ListPrefix(prefix string) ([]string, error) {
+	var out []string
+	// compare the prefix as is since the wildcards of LIKE (i.e. '_') are common in the keys
+	if err := p.db.Select(&out, "SELECT val FROM tracker_kv WHERE left(key, length($1)) = $1", prefix); err != nil {
 		return nil, err
 	}
-	defer txn.Rollback()
-
-	res := []string{}
-	c := txn.Bucket(dbConf).Cursor()
-	for k, v := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = c.Next() {
-		res = append(res, string(v))
-	}
-	return res, nil
+	return out, nil
 }
 
 // Set implements the store interface
-func (b *BoltStore) Set(k, v string) error {
-	txn, err := b.conn.Begin(true)
-	if err != nil {
-		return err
-	}
-	defer txn.Rollback()
-
-	bucket := txn.Bucket(dbConf)
-	if err := bucket.Put([]byte(k), []byte(v)); err != nil {
+func (p *TrackerStore) Set(k, v string) error {
+	if _, err := p.db.Exec("INSERT INTO tracker_kv (key, val) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET val = $2", k, v); err != nil {
 		return err
 	}
-	return txn.Commit()
+	return nil
 }
 
 // GetEntry implements the store interface
-func (b *BoltStore) GetEntry(hash string) (store.Entry, error) {
-	txn, err := b.conn.Begin(true)
-	if err != nil {
-		return nil, err
+func (p *TrackerStore) GetEntry(hash string) (store.Entry, error) {
+	e := &TrackerEntry{
+		db:   p.db,
+		hash: hash,
 	}
-	defer txn.Rollback()
+	return e, nil
+}
 
-	bucketName := append(dbLogs, []byte(hash)...)
-	if _, err := txn.CreateBucketIfNotExists(bucketName); err != nil {
-		return nil, err
-	}
-	if err := txn.Commit(); err != nil {
-		return nil, err
-	}
-	e := &Entry{
-		conn:   b.conn,
-		bucket: bucketName,
+// RemoveLogsAfter removes the logs of the entry included after the block 'num'
+func (p *TrackerStore) RemoveLogsAfter(hash string, num uint64) error {
+	if _, err := p.db.Exec("DELETE FROM tracker_logs WHERE entry = $1 AND block_num > $2", hash, num); err != nil {
+		return err
 	}
-	return e, nil
+	return nil
 }
 
-// Entry is an store.Entry implementation
-type Entry struct {
-	conn   *bolt.DB
-	bucket []byte
+// TrackerEntry is an store.Entry implementation
+type TrackerEntry struct {
+	db   *sqlx.DB
+	hash string
 }
 
 // LastIndex implements the store interface
-func (e *Entry) LastIndex() (uint64, error) {
-	tx, err := e.conn.Begin(false)
-	if err != nil {
+func (e *TrackerEntry) LastIndex() (uint64, error) {
+	var index uint64
+	if err := e.db.Get(&index, "SELECT indx FROM tracker_logs WHERE entry = $1 ORDER BY indx DESC LIMIT 1", e.hash); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
 		return 0, err
 	}
-	defer tx.Rollback()
-
-	curs := tx.Bucket(e.bucket).Cursor()
-	if last, _ := curs.Last(); last != nil {
-		return bytesToUint64(last) + 1, nil
-	}
-	return 0, nil
+	return index + 1, nil
 }
 
 // StoreLog implements the store interface
-func (e *Entry) StoreLog(log *web3.Log) error {
+func (e *TrackerEntry) StoreLog(log *web3.Log) error {
 	return e.StoreLogs([]*web3.Log{log})
 }
 
 // StoreLogs implements the store interface
-func (e *Entry) StoreLogs(logs []*web3.Log) error {
+func (e *TrackerEntry) StoreLogs(logs []*web3.Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	lastIndex, err := e.LastIndex()
+	if err != nil {
+		return err
+	}
+
+	txn, err := e.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	query := "INSERT INTO tracker_logs (entry, indx, log_index, tx_index, tx_hash, block_num, block_hash, address, topics, data) VALUES (:entry, :indx, :log_index, :tx_index, :tx_hash, :block_num, :block_hash, :address, :topics, :data)"
+
+	for indx, log := range logs {
+		evnt := proto.DecodeEvent(log)
+		obj := &trackerLog{
+			Entry:     e.hash,
+			Index:     lastIndex + uint64(indx),
+			LogIndex:  evnt.LogIndex,
+			TxIndex:   evnt.TxIndex,
+			TxHash:    evnt.TxHash,
+			BlockNum:  evnt.BlockNum,
+			BlockHash: evnt.BlockHash,
+			Address:   evnt.Address,
+			Topics:    evnt.Topics,
+			Data:      evnt.Data,
+		}
+		if _, err := txn.NamedExec(query, obj); err != nil {
+			return err
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		return err
+	}
 	return nil
 }
 
 // RemoveLogs implements the store interface
-func (e *Entry) RemoveLogs(indx uint64) error {
+func (e *TrackerEntry) RemoveLogs(indx uint64) error {
+	if _, err := e.db.Exec("DELETE FROM tracker_logs WHERE entry = $1 AND indx >= $2", e.hash, indx); err != nil {
+		return err
+	}
 	return nil
 }
 
 // GetLog implements the store interface
-func (e *Entry) GetLog(indx uint64, log *web3.Log) error {
+func (e *TrackerEntry) GetLog(indx uint64, log *web3.Log) error {
+	obj := trackerLog{}
+	if err := e.db.Get(&obj, "SELECT * FROM tracker_logs WHERE entry = $1 AND indx = $2", e.hash, indx); err != nil {
+		return err
+	}
+
+	evnt := &proto.Event{
+		LogIndex:  obj.LogIndex,
+		TxIndex:   obj.TxIndex,
+		TxHash:    obj.TxHash,
+		BlockNum:  obj.BlockNum,
+		BlockHash: obj.BlockHash,
+		Address:   obj.Address,
+		Topics:    obj.Topics,
+		Data:      obj.Data,
+	}
+	res, err := evnt.ToLog()
+	if err != nil {
+		return err
+	}
+	*log = *res
 	return nil
 }
 
-func bytesToUint64(b []byte) uint64 {
-	return binary.BigEndian.Uint64(b)
+type trackerLog struct {
+	Entry     string `db:"entry"`
+	Index     uint64 `db:"indx"`
+	LogIndex  uint64 `db:"log_index"`
+	TxIndex   uint64 `db:"tx_index"`
+	TxHash    string `db:"tx_hash"`
+	BlockNum  uint64 `db:"block_num"`
+	BlockHash string `db:"block_hash"`
+	Address   string `db:"address"`
+	Topics    string `db:"topics"`
+	Data      string `db:"data"`
 }
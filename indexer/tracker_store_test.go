@@ -0,0 +1,72 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/go-web3"
+	"github.com/umbracle/go-web3/tracker/store"
+)
+
+func TestTrackerStore(t *testing.T) {
+	store.TestStore(t, func(t *testing.T) (store.Store, func()) {
+		db, close := setupPostgresql(t)
+
+		// create the tables
+		if _, err := newStateWithDB(db); err != nil {
+			t.Fatal(err)
+		}
+		return NewTrackerStore(db), close
+	})
+}
+
+func TestTrackerStore_ListPrefix(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	_, err := newStateWithDB(db)
+	assert.NoError(t, err)
+
+	s := NewTrackerStore(db)
+	assert.NoError(t, s.Set("lastBlock_a", "1"))
+	assert.NoError(t, s.Set("lastBlockXa", "2"))
+	assert.NoError(t, s.Set("lastBlock%a", "3"))
+
+	// the wildcards of the prefix are not expanded
+	vals, err := s.ListPrefix("lastBlock_")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, vals)
+
+	vals, err = s.ListPrefix("lastBlock%")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3"}, vals)
+}
+
+func TestTrackerStore_Prune(t *testing.T) {
+	db, close := setupPostgresql(t)
+	defer close()
+
+	state, err := newStateWithDB(db)
+	assert.NoError(t, err)
+	state.journalDepth = 2
+
+	entry, err := NewTrackerStore(db).GetEntry("track")
+	assert.NoError(t, err)
+
+	logs := []*web3.Log{}
+	for i := uint64(1); i <= 5; i++ {
+		logs = append(logs, &web3.Log{BlockNumber: i, BlockHash: web3.Hash{byte(i)}})
+	}
+	assert.NoError(t, entry.StoreLogs(logs))
+
+	// the logs before the window are pruned except the last one
+	assert.NoError(t, state.ApplyDiff(&BlockDiff{Track: "track", Number: 5}, true))
+
+	var blocks []uint64
+	assert.NoError(t, db.Select(&blocks, "SELECT block_num FROM tracker_logs WHERE entry = 'track' ORDER BY indx"))
+	assert.Equal(t, []uint64{2, 3, 4, 5}, blocks)
+
+	index, err := entry.LastIndex()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), index)
+}
@@ -0,0 +1,105 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/eth-indexer/indexer/tracker"
+	"github.com/umbracle/eth-indexer/sdk"
+	"github.com/umbracle/go-web3"
+)
+
+func TestTracker_SourceFilter(t *testing.T) {
+	source := &sdk.Source{
+		Name:       "source",
+		Address:    []web3.Address{{0x1}, {0x2}},
+		Topics:     []web3.Hash{{0x3}, {0x4}},
+		StartBlock: 100,
+	}
+
+	track := trackFromSource("provider", source)
+	assert.Equal(t, track.FromAddr, web3.Address{0x1}.String()+","+web3.Address{0x2}.String())
+
+	config, err := filterConfigFromTracker(track)
+	assert.NoError(t, err)
+	assert.Equal(t, config.Hash, "provider.source")
+	assert.Equal(t, config.Start, uint64(100))
+	assert.Equal(t, config.Address, source.Address)
+	assert.Len(t, config.To, 0)
+
+	// more than one topic is filtered on the client
+	assert.Len(t, config.Topics, 0)
+
+	logs := []*web3.Log{
+		{Topics: []web3.Hash{{0x3}}},
+		{Topics: []web3.Hash{{0x5}}},
+		{Topics: []web3.Hash{{0x4}}},
+		{},
+	}
+	assert.Len(t, filterLogs(logs, source.Topics), 2)
+	assert.Len(t, filterLogs(logs, nil), 4)
+
+	// a single topic is part of the log filter
+	source.Topics = source.Topics[:1]
+
+	config, err = filterConfigFromTracker(trackFromSource("provider", source))
+	assert.NoError(t, err)
+	assert.Equal(t, *config.Topics[0], web3.Hash{0x3})
+}
+
+func TestTracker_FailedProviderFilter(t *testing.T) {
+	srv := &trackerSrv{
+		logger: hclog.NewNullLogger(),
+		progress: map[string]*trackProgress{
+			"failed": newTrackProgress("failed", "p1", 0, 0),
+			"live":   newTrackProgress("live", "p2", 0, 0),
+		},
+	}
+
+	newFilter := func() *tracker.Filter {
+		return &tracker.Filter{
+			SyncCh:  make(chan uint64, 1),
+			EventCh: make(chan *tracker.Event),
+			DoneCh:  make(chan struct{}, 1),
+		}
+	}
+
+	// the first provider already failed
+	failed := &providerSrv{name: "p1", logger: hclog.NewNullLogger(), err: assert.AnError}
+	failedFilter := newFilter()
+	go srv.trackFilter(&proto.Track{Name: "failed"}, failed, failedFilter, nil)
+
+	live := &providerSrv{name: "p2", logger: hclog.NewNullLogger()}
+	liveFilter := newFilter()
+	go srv.trackFilter(&proto.Track{Name: "live"}, live, liveFilter, nil)
+
+	// the tracker emits the events of the blocks to the filters in order
+	doneCh := make(chan struct{})
+	go func() {
+		for i := uint64(1); i <= 10; i++ {
+			failedFilter.EventCh <- &tracker.Event{
+				Added: []*web3.Log{{BlockNumber: i}},
+			}
+			liveFilter.EventCh <- &tracker.Event{}
+			liveFilter.SyncCh <- i
+		}
+		close(doneCh)
+	}()
+
+	select {
+	case <-doneCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the filter of the failed provider blocks the tracker")
+	}
+
+	// the other provider keeps advancing
+	assert.Eventually(t, func() bool {
+		p := srv.getProgress("live")
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		return p.block == 10
+	}, time.Second, 10*time.Millisecond)
+}
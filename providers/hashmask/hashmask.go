@@ -23,7 +23,7 @@ func Provider() *sdk.Provider {
 						},
 						{
 							Name: "name",
-							Type: sdk.TypeAddress,
+							Type: sdk.TypeString,
 						},
 					},
 				},
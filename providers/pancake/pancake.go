@@ -36,7 +36,8 @@ func Provider() *sdk.Provider {
 	return &sdk.Provider{
 		Resources: schema,
 		Filter: &sdk.FilterByAddr{
-			// Catch all the events generated by the factory and router
+			// Catch all the events generated by the factory and router.
+			// The events of each pair are tracked with the pair template.
 			FromAddr: factoryAddr,
 			ToAddr:   routerAddr,
 		},
@@ -68,47 +69,67 @@ func Provider() *sdk.Provider {
 					ecosystem.Incr("numPairs")
 
 					// Add pair info
-					pair := req.Get("pair", vals["pair"].(web3.Address).String())
+					pairAddr := vals["pair"].(web3.Address)
+					pair := req.Get("pair", pairAddr.String())
 					pair.Set("token0", t0.String())
 					pair.Set("token1", t1.String())
 
 					// Add token num Pairs
 					t0T.Incr("numPairs")
 					t1T.Incr("numPairs")
+
+					// track the events of the new pair
+					req.Track("pair", pairAddr)
 				},
 			},
-			{
-				// mint event
-				Type: evntMint,
-				Handler: func(req *sdk.HandlerReq) {
-					liquidityEvent(req, "mint")
-				},
-			},
-			{
-				// burn event
-				Type: evntBurn,
-				Handler: func(req *sdk.HandlerReq) {
-					liquidityEvent(req, "burn")
-				},
-			},
-			{
-				// swap event
-				Type: evntSwap,
-				Handler: func(req *sdk.HandlerReq) {
-					handleSwap(req)
-				},
+		},
+		Templates: map[string]*sdk.Template{
+			"pair": {
+				Trackers: pairTrackers,
 			},
 		},
 		Snapshots: map[string]*sdk.Snapshot2{
 			"tokens_numPairs": {
-				Table:     "token",
-				Index:     []string{"numPairs"},
-				SplitFunc: sdk.BlockSplitFunc(100),
+				Table: "token",
+				Index: []string{"numPairs"},
+				Split: sdk.BlockSplit(100),
+			},
+		},
+		Candles: map[string]*sdk.Candle{
+			"pair_token0Price_hourly": {
+				Table:    "pair",
+				Field:    "token0Price",
+				Interval: sdk.HourSplit(),
 			},
 		},
 	}
 }
 
+// pairTrackers are the trackers for the events of each pair
+var pairTrackers = []*sdk.Tracker{
+	{
+		// mint event
+		Type: evntMint,
+		Handler: func(req *sdk.HandlerReq) {
+			liquidityEvent(req, "mint")
+		},
+	},
+	{
+		// burn event
+		Type: evntBurn,
+		Handler: func(req *sdk.HandlerReq) {
+			liquidityEvent(req, "burn")
+		},
+	},
+	{
+		// swap event
+		Type: evntSwap,
+		Handler: func(req *sdk.HandlerReq) {
+			handleSwap(req)
+		},
+	},
+}
+
 func handleSwap(req *sdk.HandlerReq) {
 	swapEvent := &SwapEvent{}
 	if err := sdk.DecodeEvent(&swapEvent, req.Vals); err != nil {
@@ -165,7 +186,7 @@ type token struct {
 }
 
 func (t *token) ToDecimals(i *big.Int) *sdk.Float {
-	return new(sdk.Float).SetBigInt(i).DivUint(t.Get("decimals").(uint64))
+	return new(sdk.Float).SetBigInt(i).DivUint(t.Get("decimals").(*big.Int).Uint64())
 }
 
 func loadEnsemble(req *sdk.HandlerReq, addr string) *Ensemble {
@@ -194,8 +215,8 @@ func handleSync(req *sdk.HandlerReq, ensemble *Ensemble, event proto.Event) {
 	reserve0 := vals["reserve0"].(*big.Int)
 	reserve1 := vals["reserve1"].(*big.Int)
 
-	reserve0Dec := new(sdk.Float).SetBigInt(reserve0).DivUint(ensemble.Token0.Get("decimals").(uint64))
-	reserve1Dec := new(sdk.Float).SetBigInt(reserve1).DivUint(ensemble.Token1.Get("decimals").(uint64))
+	reserve0Dec := new(sdk.Float).SetBigInt(reserve0).DivUint(ensemble.Token0.Get("decimals").(*big.Int).Uint64())
+	reserve1Dec := new(sdk.Float).SetBigInt(reserve1).DivUint(ensemble.Token1.Get("decimals").(*big.Int).Uint64())
 
 	ensemble.Pair.Set("token0Price", reserve0Dec.Div(reserve1Dec))
 	ensemble.Pair.Set("token1Price", reserve1Dec.Div(reserve0Dec))
@@ -211,8 +232,8 @@ func liquidityEvent(req *sdk.HandlerReq, typ string) {
 	amount0 := req.Vals["amount0"].(*big.Int)
 	amount1 := req.Vals["amount1"].(*big.Int)
 
-	amount0Dec := new(sdk.Float).SetBigInt(amount0).DivUint(ensemble.Token0.Get("decimals").(uint64))
-	amount1Dec := new(sdk.Float).SetBigInt(amount1).DivUint(ensemble.Token1.Get("decimals").(uint64))
+	amount0Dec := new(sdk.Float).SetBigInt(amount0).DivUint(ensemble.Token0.Get("decimals").(*big.Int).Uint64())
+	amount1Dec := new(sdk.Float).SetBigInt(amount1).DivUint(ensemble.Token1.Get("decimals").(*big.Int).Uint64())
 
 	obj.Set("amount0", amount0Dec)
 	obj.Set("amount1", amount1Dec)
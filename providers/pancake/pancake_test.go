@@ -45,7 +45,7 @@ func TestPancakeMint(t *testing.T) {
 	// expectedLiquidity := expandTo18decimals(2)
 
 	// Process stuff
-	diff := h.Process(h.SendTxn(h.pair.Mint(h.srv.Owner())))
+	diff := h.ProcessPair(h.SendTxn(h.pair.Mint(h.srv.Owner())))
 
 	fmt.Println("-- diff --")
 	fmt.Println(diff)
@@ -73,7 +73,7 @@ func TestPancakeSwapToken0(t *testing.T) {
 	h.transferToken(h.token0, h.pairAddr, swapAmount)
 
 	expectedOutputAmount, _ := new(big.Int).SetString("1662497915624478906", 10)
-	diff := h.Process(h.SendTxn(h.pair.Swap(big.NewInt(0), expectedOutputAmount, h.srv.Owner(), []byte{})))
+	diff := h.ProcessPair(h.SendTxn(h.pair.Swap(big.NewInt(0), expectedOutputAmount, h.srv.Owner(), []byte{})))
 
 	fmt.Println("-- diff --")
 	fmt.Println(diff)
@@ -164,6 +164,15 @@ type Harness struct {
 }
 
 func (h *Harness) Process(txn *contract.Txn) []*protosdk.Diff {
+	return h.process(txn, "")
+}
+
+// ProcessPair processes the transaction with the trackers of the pair template
+func (h *Harness) ProcessPair(txn *contract.Txn) []*protosdk.Diff {
+	return h.process(txn, "pair")
+}
+
+func (h *Harness) process(txn *contract.Txn, template string) []*protosdk.Diff {
 	rr := txn.Receipt()
 
 	fmt.Println("- process event -")
@@ -177,14 +186,26 @@ func (h *Harness) Process(txn *contract.Txn) []*protosdk.Diff {
 		fmt.Println(l.Topics[0].String())
 	}
 
+	block, err := h.srv.Provider().Eth().GetBlockByNumber(web3.BlockNumber(rr.BlockNumber), false)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+
 	act := &sdk.Action{
 		BlockNum: txn.Receipt().BlockNumber,
 		Events:   events,
+		Template: template,
+		Block: &sdk.Block{
+			Number:     block.Number,
+			Hash:       block.Hash,
+			ParentHash: block.ParentHash,
+			Timestamp:  block.Timestamp,
+		},
 	}
-	diff, err := h.indexer.Process(act)
+	diff, evntErr := h.indexer.Process(act)
 
 	fmt.Println("-- err --")
-	fmt.Println(err)
+	fmt.Println(evntErr)
 
 	return diff
 }
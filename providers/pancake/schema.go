@@ -6,7 +6,6 @@ import (
 
 	"github.com/umbracle/eth-indexer/sdk"
 	"github.com/umbracle/go-web3"
-	"github.com/umbracle/go-web3/jsonrpc"
 )
 
 var tokenCaller *sdk.ContractCaller
@@ -177,7 +176,7 @@ var schema = map[string]*sdk.Resource{
 				},
 			},
 		},
-		Init: func(addr web3.Address, provider *jsonrpc.Client, obj *sdk.Obj2) error {
+		Init: func(addr web3.Address, provider sdk.Client, obj *sdk.Obj2) error {
 			name, err := tokenCaller.Call("name", addr, provider)
 			if err != nil {
 				name = "empty"
@@ -273,6 +272,12 @@ var schema = map[string]*sdk.Resource{
 					Type: sdk.TypeDecimal,
 				},
 			},
+			Indexes: []*sdk.Index{
+				{
+					// swaps of a pair
+					Fields: []sdk.IndexField{sdk.Asc("pair")},
+				},
+			},
 		},
 	},
 }
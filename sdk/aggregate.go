@@ -0,0 +1,235 @@
+package sdk
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// AggregateKind is the kind of aggregation of the changes of a field
+// in the window of a snapshot
+type AggregateKind int
+
+const (
+	// AggregateCount is the number of blocks in which the field changed
+	AggregateCount AggregateKind = iota + 1
+
+	// AggregateSum is the sum of the deltas of the field
+	AggregateSum
+
+	// AggregateMin is the lowest value of the field
+	AggregateMin
+
+	// AggregateMax is the highest value of the field
+	AggregateMax
+
+	// AggregateFirst is the first value of the field
+	AggregateFirst
+
+	// AggregateLast is the last value of the field
+	AggregateLast
+
+	// AggregateAvg is the average of the values of the field
+	AggregateAvg
+)
+
+func (k AggregateKind) String() string {
+	switch k {
+	case AggregateCount:
+		return "count"
+	case AggregateSum:
+		return "sum"
+	case AggregateMin:
+		return "min"
+	case AggregateMax:
+		return "max"
+	case AggregateFirst:
+		return "first"
+	case AggregateLast:
+		return "last"
+	case AggregateAvg:
+		return "avg"
+	default:
+		return fmt.Sprintf("AggregateKind(%d)", int(k))
+	}
+}
+
+// Aggregate is a column of a snapshot with the aggregation of
+// the changes of a field of the table in each window
+type Aggregate struct {
+	Field string
+	Kind  AggregateKind
+
+	// Name is the name of the column (default <field>_<kind>)
+	Name string
+}
+
+func (a *Aggregate) column() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return a.Field + "_" + a.Kind.String()
+}
+
+// samplesColumn is the column with the number of values of an average
+func (a *Aggregate) samplesColumn() string {
+	return a.column() + "_samples"
+}
+
+func isNumeric(typ FieldType) bool {
+	switch typ {
+	case TypeUint, TypeInt, TypeBigInt, TypeDecimal:
+		return true
+	default:
+		return false
+	}
+}
+
+// aggregateType returns the type of the column of the aggregate for a field
+// of the given type or false if the field cannot be aggregated that way
+func aggregateType(kind AggregateKind, typ FieldType) (FieldType, bool) {
+	switch kind {
+	case AggregateCount:
+		return TypeUint, true
+	case AggregateFirst, AggregateLast:
+		return typ, true
+	case AggregateSum:
+		// the deltas of an unsigned integer might be negative
+		if typ == TypeDecimal {
+			return TypeDecimal, true
+		}
+		return TypeBigInt, isNumeric(typ)
+	case AggregateMin, AggregateMax:
+		return typ, isNumeric(typ) || typ == TypeTimestamp
+	case AggregateAvg:
+		return TypeDecimal, isNumeric(typ)
+	default:
+		return 0, false
+	}
+}
+
+// aggregateFields returns the columns of the aggregates in the snapshot
+func aggregateFields(table *Table, aggregates []*Aggregate) []*Field {
+	fields := []*Field{}
+	for _, a := range aggregates {
+		typ, _ := aggregateType(a.Kind, table.getField(a.Field).Type)
+		fields = append(fields, &Field{Name: a.column(), Type: typ})
+		if a.Kind == AggregateAvg {
+			fields = append(fields, &Field{Name: a.samplesColumn(), Type: TypeUint})
+		}
+	}
+	return fields
+}
+
+// aggregate updates the aggregate in the entry of the snapshot with the
+// change of the field. The previous value is empty if the object is new.
+func (a *Aggregate) aggregate(entry *Obj2, field *Field, prev, val string) {
+	col := a.column()
+
+	decode := func(str string) interface{} {
+		v, err := field.Decode(str)
+		if err != nil {
+			entry.objErr.finish(&ErrorEvent{
+				Type: ErrorEventGetDecode,
+				Err:  fmt.Errorf("failed to decode %s: %v", field.Name, err),
+			})
+		}
+		return v
+	}
+
+	switch a.Kind {
+	case AggregateCount:
+		entry.Add(col, uint64(1))
+
+	case AggregateSum:
+		if field.Type == TypeDecimal {
+			delta := decode(val).(*Float)
+			if prev != "" {
+				delta = delta.Sub(decode(prev).(*Float))
+			}
+			if current, ok := entry.GetOk(col); ok {
+				delta = current.(*Float).Add(delta)
+			}
+			entry.Set(col, delta)
+		} else {
+			delta, _ := toBigInt(decode(val))
+			if prev != "" {
+				prevVal, _ := toBigInt(decode(prev))
+				delta = new(big.Int).Sub(delta, prevVal)
+			}
+			entry.Add(col, delta)
+		}
+
+	case AggregateMin, AggregateMax:
+		v := decode(val)
+		if current, ok := entry.GetOk(col); ok {
+			cmp := compareValues(v, current)
+			if (a.Kind == AggregateMin && cmp >= 0) || (a.Kind == AggregateMax && cmp <= 0) {
+				return
+			}
+		}
+		entry.Set(col, v)
+
+	case AggregateFirst:
+		if _, ok := entry.GetOk(col); !ok {
+			entry.Set(col, decode(val))
+		}
+
+	case AggregateLast:
+		entry.Set(col, decode(val))
+
+	case AggregateAvg:
+		// running mean: avg = avg + (val - avg) / n
+		v := toFloat(decode(val))
+
+		avg, samples := new(Float), new(big.Int)
+		if current, ok := entry.GetOk(col); ok {
+			avg = current.(*Float)
+		}
+		if current, ok := entry.GetOk(a.samplesColumn()); ok {
+			samples = current.(*big.Int)
+		}
+		samples = new(big.Int).Add(samples, big.NewInt(1))
+
+		avg = avg.Add(v.Sub(avg).Div(new(Float).SetBigInt(samples)))
+		entry.Set(col, avg)
+		entry.Set(a.samplesColumn(), samples)
+	}
+}
+
+// toFloat converts a numeric value to a decimal
+func toFloat(v interface{}) *Float {
+	if f, ok := v.(*Float); ok {
+		return f
+	}
+	num, _ := toBigInt(v)
+	return new(Float).SetBigInt(num)
+}
+
+// compareValues compares two values of the same numeric (or timestamp) type
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case *Float:
+		return a.raw.Cmp(&b.(*Float).raw)
+	case int64:
+		b := b.(int64)
+		if a < b {
+			return -1
+		} else if a > b {
+			return 1
+		}
+		return 0
+	case *big.Int:
+		return a.Cmp(b.(*big.Int))
+	case time.Time:
+		b := b.(time.Time)
+		if a.Before(b) {
+			return -1
+		} else if a.After(b) {
+			return 1
+		}
+		return 0
+	default:
+		panic(fmt.Sprintf("cannot compare %T", a))
+	}
+}
@@ -0,0 +1,161 @@
+package sdk
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate_Snapshot(t *testing.T) {
+	var handler func(req *HandlerReq)
+
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"pair": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "address", Type: TypeAddress, ID: true},
+						{Name: "reserve", Type: TypeUint},
+						{Name: "price", Type: TypeDecimal},
+						{Name: "name", Type: TypeString},
+					},
+				},
+			},
+		},
+		Snapshots: map[string]*Snapshot2{
+			"pair_window": {
+				Table: "pair",
+				Index: []string{"reserve", "name"},
+				Aggregates: []*Aggregate{
+					{Field: "reserve", Kind: AggregateCount},
+					{Field: "reserve", Kind: AggregateSum, Name: "volume"},
+					{Field: "reserve", Kind: AggregateMin},
+					{Field: "reserve", Kind: AggregateMax},
+					{Field: "reserve", Kind: AggregateAvg},
+					{Field: "price", Kind: AggregateFirst},
+					{Field: "price", Kind: AggregateLast},
+				},
+				Split: BlockSplit(10),
+			},
+		},
+		BlockTrackers: []*BlockTracker{
+			{
+				Interval: 1,
+				Handler: func(req *HandlerReq) {
+					handler(req)
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	// the columns of the snapshot are typed
+	types := map[string]FieldType{}
+	for _, f := range p.schemas["pair_window"].Fields {
+		types[f.Name] = f.Type
+	}
+	assert.Equal(t, map[string]FieldType{
+		"address":             TypeAddress,
+		"removed":             TypeBool,
+		"reserve":             TypeUint,
+		"name":                TypeString,
+		"reserve_count":       TypeUint,
+		"volume":              TypeBigInt,
+		"reserve_min":         TypeUint,
+		"reserve_max":         TypeUint,
+		"reserve_avg":         TypeDecimal,
+		"reserve_avg_samples": TypeUint,
+		"price_first":         TypeDecimal,
+		"price_last":          TypeDecimal,
+		"block":               TypeUint,
+	}, types)
+
+	setPair := func(num uint64, reserve uint64, price string) {
+		handler = func(req *HandlerReq) {
+			f := new(Float)
+			f.SetString(price)
+
+			obj := req.Get("pair", "0x1")
+			obj.Set("reserve", reserve)
+			obj.Set("price", f)
+		}
+		_, evntErr := p.Process(&Action{BlockNum: num, BlockTick: true})
+		assert.Nil(t, evntErr)
+	}
+
+	// window 0
+	setPair(1, 10, "1.5")
+	setPair(2, 4, "2")
+	setPair(3, 16, "3")
+
+	// window 1
+	setPair(11, 20, "4")
+
+	var window0, window1 *Obj2
+	handler = func(req *HandlerReq) {
+		window0 = req.Get("pair_window", "0x1", uint64(0))
+		window1 = req.Get("pair_window", "0x1", uint64(10))
+	}
+	_, evntErr := p.Process(&Action{BlockNum: 12, BlockTick: true})
+	assert.Nil(t, evntErr)
+
+	assert.Equal(t, big.NewInt(16), window0.Get("reserve"))
+	assert.Equal(t, big.NewInt(3), window0.Get("reserve_count"))
+	assert.Equal(t, big.NewInt(16), window0.Get("volume"))
+	assert.Equal(t, big.NewInt(4), window0.Get("reserve_min"))
+	assert.Equal(t, big.NewInt(16), window0.Get("reserve_max"))
+	assert.Equal(t, "10", window0.Get("reserve_avg").(*Float).String())
+	assert.Equal(t, "1.5", window0.Get("price_first").(*Float).String())
+	assert.Equal(t, "3", window0.Get("price_last").(*Float).String())
+
+	// the sum of the deltas starts with the value before the window
+	assert.Equal(t, big.NewInt(1), window1.Get("reserve_count"))
+	assert.Equal(t, big.NewInt(4), window1.Get("volume"))
+	assert.Equal(t, big.NewInt(20), window1.Get("reserve_min"))
+
+	// the name did not change
+	_, ok := window0.GetOk("name")
+	assert.False(t, ok)
+}
+
+func TestAggregate_Validate(t *testing.T) {
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"pair": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "address", Type: TypeAddress, ID: true},
+						{Name: "reserve", Type: TypeUint},
+						{Name: "name", Type: TypeString},
+					},
+				},
+			},
+		},
+		Snapshots: map[string]*Snapshot2{
+			"pair_window": {
+				Table: "pair",
+				Index: []string{"reserve"},
+				Aggregates: []*Aggregate{
+					{Field: "name", Kind: AggregateSum},
+					{Field: "unknown", Kind: AggregateCount},
+					{Field: "reserve", Kind: AggregateMax, Name: "reserve"},
+				},
+			},
+			"pair_empty": {
+				Table: "pair",
+			},
+		},
+	}
+
+	verr, ok := p.Validate().(*ValidationError)
+	assert.True(t, ok)
+
+	expected := []string{
+		"snapshot 'pair_empty' does not have any index field or aggregate",
+		"snapshot 'pair_window': field 'name' of type string cannot be aggregated with sum",
+		"snapshot 'pair_window': aggregate field 'unknown' does not exist in table 'pair'",
+		"snapshot 'pair_window': column 'reserve' is duplicated",
+	}
+	assert.Equal(t, expected, verr.Problems)
+}
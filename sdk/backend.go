@@ -5,6 +5,7 @@ import (
 	"github.com/mitchellh/mapstructure"
 	"github.com/umbracle/eth-indexer/indexer/proto"
 	protosdk "github.com/umbracle/eth-indexer/sdk/proto"
+	"github.com/umbracle/go-web3"
 )
 
 type Backend interface {
@@ -15,7 +16,7 @@ type Backend interface {
 	Process(ac *Action) ([]*protosdk.Diff, *ErrorEvent)
 
 	// Returns filters that encode how to parse stuff
-	GetFilter() *FilterByAddr
+	GetFilter() Filter
 
 	// TODO: Return access to the state to query info
 }
@@ -25,8 +26,42 @@ type GetSchemasResponse struct {
 }
 
 type Action struct {
-	BlockNum uint64
-	Events   Events
+	BlockNum  uint64
+	BlockHash web3.Hash
+	Events    Events
+
+	// Block is the header of the block that includes the events
+	Block *Block
+
+	// Calls are the calls to the contracts in the block
+	Calls []*Call
+
+	// BlockTick is set if the block trackers run at the block
+	BlockTick bool
+
+	// Template is the template of the data source that
+	// generated the events (if any)
+	Template string
+
+	// Templates are the templates of each event if they come from several
+	// data sources (i.e. replayed from the archive) instead of the Template
+	Templates []string
+}
+
+// eventTemplate returns the template of the data source of the event
+func (a *Action) eventTemplate(indx int) string {
+	if a.Templates != nil && indx < len(a.Templates) {
+		return a.Templates[indx]
+	}
+	return a.Template
+}
+
+// Block is the metadata of a block
+type Block struct {
+	Number     uint64
+	Hash       web3.Hash
+	ParentHash web3.Hash
+	Timestamp  uint64
 }
 
 type Events []proto.Event
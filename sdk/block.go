@@ -0,0 +1,73 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/umbracle/go-web3"
+)
+
+// BlockTracker is a handler that runs every 'Interval' blocks. It is used
+// to index state that is only available with contract calls.
+type BlockTracker struct {
+	Interval   uint64
+	StartBlock uint64
+	Handler    func(*HandlerReq)
+}
+
+type blockIndexer struct {
+	tracker *BlockTracker
+}
+
+func (b *blockIndexer) Process(ac *Action, i *Snapshot) error {
+	if !ac.BlockTick {
+		return nil
+	}
+	if !b.tracker.matches(ac.BlockNum) {
+		return nil
+	}
+	req := &HandlerReq{
+		Snapshot: i,
+		Action:   ac,
+	}
+	i.handler = &handlerCtx{
+		tracker:   fmt.Sprintf("blocks-%d", b.tracker.Interval),
+		blockTick: true,
+		event:     -1,
+		call:      -1,
+	}
+	b.tracker.Handler(req)
+	i.handler = nil
+	return nil
+}
+
+func (b *BlockTracker) matches(num uint64) bool {
+	return num >= b.StartBlock && num%b.Interval == 0
+}
+
+// IsBlockTick returns true if any of the block trackers of the provider runs at the block
+func (p *Provider) IsBlockTick(num uint64) bool {
+	for _, t := range p.BlockTrackers {
+		if t.matches(num) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContractCall calls the contract with the state at the block of the request
+func (h *HandlerReq) ContractCall(caller *ContractCaller, alias string, addr web3.Address) interface{} {
+	if h.provider.client == nil {
+		h.finish(&ErrorEvent{
+			Type: ErrorEventContractCall,
+			Err:  fmt.Errorf("client not set"),
+		})
+	}
+	val, err := caller.CallAt(alias, addr, h.provider.client, web3.BlockNumber(h.Action.BlockNum))
+	if err != nil {
+		h.finish(&ErrorEvent{
+			Type: ErrorEventContractCall,
+			Err:  err,
+		})
+	}
+	return val
+}
@@ -0,0 +1,34 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockTracker(t *testing.T) {
+	blocks := []uint64{}
+	p := &Provider{
+		BlockTrackers: []*BlockTracker{
+			{
+				Interval:   10,
+				StartBlock: 20,
+				Handler: func(req *HandlerReq) {
+					blocks = append(blocks, req.Action.BlockNum)
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	assert.False(t, p.IsBlockTick(10))
+	assert.False(t, p.IsBlockTick(25))
+	assert.True(t, p.IsBlockTick(30))
+
+	// only the ticks run the block trackers
+	for _, act := range []*Action{{BlockNum: 30}, {BlockNum: 30, BlockTick: true}} {
+		_, evntErr := p.Process(act)
+		assert.Nil(t, evntErr)
+	}
+	assert.Equal(t, blocks, []uint64{30})
+}
@@ -0,0 +1,85 @@
+package sdk
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/umbracle/go-web3"
+	"github.com/umbracle/go-web3/abi"
+)
+
+// CallTracker is a handler for the calls to a method of the contracts
+// of the filter. The inputs of the call are decoded in the Vals of the
+// request and the outputs (if available) in the Outputs.
+type CallTracker struct {
+	Method  *abi.Method
+	Handler func(*HandlerReq)
+}
+
+// Call is a successful call to a contract, either from a
+// transaction or from another contract
+type Call struct {
+	TxHash  web3.Hash
+	TxIndex uint64
+	From    web3.Address
+	To      web3.Address
+	Value   *big.Int
+	Input   []byte
+
+	// Output is only available if the node supports traces
+	Output []byte
+
+	// Internal is true if the call was made by another contract
+	Internal bool
+}
+
+type callIndexer struct {
+	tracker *CallTracker
+}
+
+func (c *callIndexer) Process(ac *Action, i *Snapshot) error {
+	method := c.tracker.Method
+	id := method.ID()
+
+	for indx, call := range ac.Calls {
+		if len(call.Input) < 4 || !bytes.Equal(call.Input[:4], id) {
+			continue
+		}
+		vals, err := decodeArgs(method.Inputs, call.Input[4:])
+		if err != nil {
+			continue
+		}
+		req := &HandlerReq{
+			Snapshot: i,
+			Call:     call,
+			Vals:     vals,
+			Action:   ac,
+			Indx:     indx,
+		}
+		if len(call.Output) != 0 && method.Outputs != nil {
+			if outputs, err := decodeArgs(method.Outputs, call.Output); err == nil {
+				req.Outputs = outputs
+			}
+		}
+		i.handler = callHandlerCtx(method.Name, call, indx)
+		c.tracker.Handler(req)
+		i.handler = nil
+	}
+	return nil
+}
+
+func decodeArgs(typ *abi.Type, input []byte) (map[string]interface{}, error) {
+	if typ == nil || len(typ.TupleElems()) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	val, err := abi.Decode(typ, input)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("bad decoded type %T", val)
+	}
+	return res, nil
+}
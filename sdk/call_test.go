@@ -0,0 +1,55 @@
+package sdk
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/go-web3"
+	"github.com/umbracle/go-web3/abi"
+)
+
+func TestCallTracker(t *testing.T) {
+	method, err := abi.NewMethod("transfer(address to, uint256 amount) returns (bool)")
+	assert.NoError(t, err)
+
+	input, err := method.Inputs.Encode(map[string]interface{}{
+		"to":     web3.Address{0x1},
+		"amount": big.NewInt(10),
+	})
+	assert.NoError(t, err)
+	input = append(method.ID(), input...)
+
+	reqs := []*HandlerReq{}
+	p := &Provider{
+		CallTrackers: []*CallTracker{
+			{
+				Method: method,
+				Handler: func(req *HandlerReq) {
+					reqs = append(reqs, req)
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	act := &Action{
+		BlockNum: 1,
+		Calls: []*Call{
+			{
+				// call to another method
+				Input: []byte{0x1, 0x2, 0x3, 0x4},
+			},
+			{
+				Input: input,
+			},
+		},
+	}
+	_, evntErr := p.Process(act)
+	assert.Nil(t, evntErr)
+
+	assert.Len(t, reqs, 1)
+	assert.Equal(t, reqs[0].Vals["to"], web3.Address{0x1})
+	assert.Equal(t, reqs[0].Vals["amount"], big.NewInt(10))
+	assert.Nil(t, reqs[0].Outputs)
+}
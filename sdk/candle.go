@@ -0,0 +1,136 @@
+package sdk
+
+import (
+	"fmt"
+)
+
+// Candle is an OHLC (open, high, low and close) candle of a decimal field
+// of a table in each interval. The candle of the current interval is updated
+// with every value set in the field, including the ones in the same block.
+type Candle struct {
+	Table string
+
+	// Field is the decimal field of the table (i.e. a price)
+	Field string
+
+	// Interval is the period of the candles (i.e. sdk.HourSplit())
+	Interval *Split
+
+	// Volume is a numeric field of the table whose deltas
+	// are summed as the volume of the candle (optional)
+	Volume string
+}
+
+// aggregates returns the aggregates of the values set in the field
+func (c *Candle) aggregates() []*Aggregate {
+	return []*Aggregate{
+		{Field: c.Field, Kind: AggregateFirst, Name: "open"},
+		{Field: c.Field, Kind: AggregateMax, Name: "high"},
+		{Field: c.Field, Kind: AggregateMin, Name: "low"},
+		{Field: c.Field, Kind: AggregateLast, Name: "close"},
+	}
+}
+
+// volume returns the aggregate of the volume (if any)
+func (c *Candle) volume() *Aggregate {
+	if c.Volume == "" {
+		return nil
+	}
+	return &Aggregate{Field: c.Volume, Kind: AggregateSum, Name: "volume"}
+}
+
+func (p *Provider) buildCandle(candleName string, candle *Candle) error {
+	table, ok := p.schemas[candle.Table]
+	if !ok {
+		return fmt.Errorf("table '%s' not found", candle.Table)
+	}
+
+	candleSchema := &Table{
+		Name:   candleName,
+		Fields: []*Field{},
+	}
+	for _, field := range table.getIDS() {
+		candleSchema.Fields = append(candleSchema.Fields, &Field{Name: field.Name, Type: field.Type, ID: true})
+	}
+
+	aggregates := candle.aggregates()
+	if volume := candle.volume(); volume != nil {
+		aggregates = append(aggregates, volume)
+	}
+	candleSchema.Fields = append(candleSchema.Fields, aggregateFields(table, aggregates)...)
+
+	// the start of the interval
+	candleSchema.Fields = append(candleSchema.Fields, &Field{
+		Name: candle.Interval.Column,
+		ID:   true,
+		Type: candle.Interval.Type,
+	})
+
+	p.addSchema(candleName, candleSchema)
+	p.indexers = append(p.indexers, &candleIndexer{
+		candle:     candle,
+		candleName: candleName,
+		table:      table,
+	})
+	return nil
+}
+
+type candleIndexer struct {
+	candle     *Candle
+	candleName string
+	table      *Table
+}
+
+func (c *candleIndexer) Process(ac *Action, i *Snapshot) error {
+	header := ac.Block
+	if header == nil {
+		header = &Block{Number: ac.BlockNum}
+	}
+	interval := c.candle.Interval
+	if interval.Type == TypeTimestamp && header.Timestamp == 0 {
+		return fmt.Errorf("candle '%s' requires the timestamp of the block %d", c.candleName, ac.BlockNum)
+	}
+	bucket := interval.Func(header)
+
+	field := c.table.getField(c.candle.Field)
+	volume := c.candle.volume()
+
+	// the candles are tracked while looping
+	tracked := append([]*Obj2{}, i.trackedOrder...)
+	for _, obj := range tracked {
+		if obj.table != c.candle.Table {
+			continue
+		}
+		values := obj.sets[c.candle.Field]
+
+		var volumeVal string
+		var volumeOk bool
+		if volume != nil {
+			volumeVal, volumeOk = obj.hasChanged(volume.Field)
+		}
+		if len(values) == 0 && !volumeOk {
+			continue
+		}
+
+		kk := []interface{}{}
+		for _, idField := range c.table.getIDS() {
+			val, err := idField.Decode(obj.key[idField.Name])
+			if err != nil {
+				return err
+			}
+			kk = append(kk, val)
+		}
+		kk = append(kk, bucket)
+
+		entry := i.Get(c.candleName, kk...)
+		for _, val := range values {
+			for _, a := range c.candle.aggregates() {
+				a.aggregate(entry, field, "", val)
+			}
+		}
+		if volumeOk {
+			volume.aggregate(entry, c.table.getField(volume.Field), obj.vals[volume.Field], volumeVal)
+		}
+	}
+	return nil
+}
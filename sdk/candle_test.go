@@ -0,0 +1,155 @@
+package sdk
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCandle_Live(t *testing.T) {
+	var handler func(req *HandlerReq)
+
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"pair": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "address", Type: TypeAddress, ID: true},
+						{Name: "price", Type: TypeDecimal, Default: Float0},
+						{Name: "volume", Type: TypeUint, Default: uint64(0)},
+					},
+				},
+			},
+		},
+		Candles: map[string]*Candle{
+			"pair_hourly": {
+				Table:    "pair",
+				Field:    "price",
+				Interval: HourSplit(),
+				Volume:   "volume",
+			},
+		},
+		BlockTrackers: []*BlockTracker{
+			{
+				Interval: 1,
+				Handler: func(req *HandlerReq) {
+					handler(req)
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	types := map[string]FieldType{}
+	for _, f := range p.schemas["pair_hourly"].Fields {
+		types[f.Name] = f.Type
+	}
+	assert.Equal(t, map[string]FieldType{
+		"address":   TypeAddress,
+		"open":      TypeDecimal,
+		"high":      TypeDecimal,
+		"low":       TypeDecimal,
+		"close":     TypeDecimal,
+		"volume":    TypeBigInt,
+		"timestamp": TypeTimestamp,
+	}, types)
+
+	hour := time.Date(2021, 3, 10, 15, 0, 0, 0, time.UTC)
+	process := func(num uint64, ts time.Time, fn func(req *HandlerReq)) {
+		handler = fn
+		act := &Action{BlockNum: num, BlockTick: true, Block: &Block{Number: num, Timestamp: uint64(ts.Unix())}}
+		_, evntErr := p.Process(act)
+		assert.Nil(t, evntErr)
+	}
+	trade := func(prices ...string) func(req *HandlerReq) {
+		return func(req *HandlerReq) {
+			obj := req.Get("pair", "0x1")
+			for _, price := range prices {
+				f := new(Float)
+				f.SetString(price)
+				obj.Set("price", f)
+				obj.Add("volume", uint64(10))
+			}
+		}
+	}
+
+	var candle *Obj2
+	current := func(num uint64, ts time.Time) *Obj2 {
+		process(num, ts, func(req *HandlerReq) {
+			candle = req.Get("pair_hourly", "0x1", hour)
+		})
+		return candle
+	}
+	str := func(obj *Obj2, key string) string {
+		return obj.Get(key).(*Float).String()
+	}
+
+	// the intermediate values of the block are included
+	process(1, hour.Add(time.Minute), trade("5", "9", "2", "4"))
+
+	candle = current(2, hour.Add(2*time.Minute))
+	assert.Equal(t, "5", str(candle, "open"))
+	assert.Equal(t, "9", str(candle, "high"))
+	assert.Equal(t, "2", str(candle, "low"))
+	assert.Equal(t, "4", str(candle, "close"))
+	assert.Equal(t, big.NewInt(40), candle.Get("volume"))
+
+	// the candle of the interval is updated live
+	process(3, hour.Add(30*time.Minute), trade("10"))
+
+	candle = current(4, hour.Add(31*time.Minute))
+	assert.Equal(t, "5", str(candle, "open"))
+	assert.Equal(t, "10", str(candle, "high"))
+	assert.Equal(t, "10", str(candle, "close"))
+	assert.Equal(t, big.NewInt(50), candle.Get("volume"))
+
+	// a new interval
+	process(5, hour.Add(61*time.Minute), trade("7"))
+
+	hour = hour.Add(time.Hour)
+	candle = current(6, hour.Add(2*time.Minute))
+	assert.Equal(t, "7", str(candle, "open"))
+	assert.Equal(t, "7", str(candle, "low"))
+	assert.Equal(t, big.NewInt(10), candle.Get("volume"))
+}
+
+func TestCandle_Validate(t *testing.T) {
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"pair": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "address", Type: TypeAddress, ID: true},
+						{Name: "name", Type: TypeString},
+					},
+				},
+			},
+		},
+		Candles: map[string]*Candle{
+			"pair_candle": {
+				Table:  "pair",
+				Field:  "name",
+				Volume: "unknown",
+			},
+			"pair": {
+				Table:    "pair",
+				Field:    "price",
+				Interval: DaySplit(),
+			},
+		},
+	}
+
+	verr, ok := p.Validate().(*ValidationError)
+	assert.True(t, ok)
+
+	expected := []string{
+		"candle 'pair' has the same name as a table",
+		"candle 'pair': field 'price' does not exist in table 'pair'",
+		"candle 'pair_candle': field 'name' is string but it must be a decimal",
+		"candle 'pair_candle': volume field 'unknown' does not exist in table 'pair'",
+		"candle 'pair_candle' without interval",
+	}
+	assert.Equal(t, expected, verr.Problems)
+}
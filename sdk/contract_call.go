@@ -1,12 +1,12 @@
 package sdk
 
 import (
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/umbracle/go-web3"
 	"github.com/umbracle/go-web3/abi"
-	"github.com/umbracle/go-web3/contract"
-	"github.com/umbracle/go-web3/jsonrpc"
 )
 
 type ContractCaller struct {
@@ -56,7 +56,12 @@ func (c *ContractCaller) AddCaller(name string, callers ...*Caller) error {
 	return nil
 }
 
-func (c *ContractCaller) Call(alias string, addr web3.Address, provider *jsonrpc.Client) (interface{}, error) {
+func (c *ContractCaller) Call(alias string, addr web3.Address, provider Client) (interface{}, error) {
+	return c.CallAt(alias, addr, provider, web3.Latest)
+}
+
+// CallAt calls the contract with the state at the given block
+func (c *ContractCaller) CallAt(alias string, addr web3.Address, provider Client, block web3.BlockNumber) (interface{}, error) {
 	grp, ok := c.abis[alias]
 	if !ok {
 		panic("Not found")
@@ -68,26 +73,37 @@ func (c *ContractCaller) Call(alias string, addr web3.Address, provider *jsonrpc
 		for k := range item.abi.Methods {
 			methodName = k
 		}
-
-		fmt.Println(methodName)
-
-		c1 := contract.NewContract(addr, item.abi, provider)
-		vals, err := c1.Call(methodName, web3.Latest)
+		vals, err := callMethod(provider, addr, item.abi.Methods[methodName], block)
 		if err != nil {
-			fmt.Println("- not found -")
+			// try with the next method of the group
 			continue
 		}
 
-		fmt.Println("-- vals --")
-		fmt.Println(vals)
-
 		ret := vals["0"]
 		if item.c.DecodeHook != nil {
 			ret = item.c.DecodeHook(ret)
 		}
 		return ret, nil
 	}
-
-	fmt.Println("- end empty handed -")
 	return nil, fmt.Errorf("not found")
 }
+
+// callMethod calls a method without arguments of the contract with eth_call
+func callMethod(provider Client, addr web3.Address, method *abi.Method, block web3.BlockNumber) (map[string]interface{}, error) {
+	msg := &web3.CallMsg{
+		To:   &addr,
+		Data: method.ID(),
+	}
+	var out string
+	if err := provider.Call("eth_call", &out, msg, block.String()); err != nil {
+		return nil, err
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(out, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("empty response")
+	}
+	return decodeArgs(method.Outputs, raw)
+}
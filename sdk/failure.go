@@ -0,0 +1,141 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/go-web3"
+)
+
+// FailurePolicy is how the indexer handles the errors of the handlers
+type FailurePolicy string
+
+const (
+	// FailureStop stops the provider while the other providers keep
+	// indexing. It is the default policy.
+	FailureStop FailurePolicy = "stop"
+
+	// FailureHalt stops the indexer process
+	FailureHalt FailurePolicy = "halt"
+
+	// FailureRetry processes the block again with backoff until it succeeds
+	FailureRetry FailurePolicy = "retry"
+
+	// FailureSkip skips the event (or call) that failed and records
+	// it in the dead letters
+	FailureSkip FailurePolicy = "skip"
+
+	// FailureQuarantine skips the event (or call) that failed and all
+	// the future events and calls of the same contract
+	FailureQuarantine FailurePolicy = "quarantine"
+)
+
+// ParseFailurePolicy parses the name of a failure policy
+func ParseFailurePolicy(str string) (FailurePolicy, error) {
+	switch policy := FailurePolicy(str); policy {
+	case FailureStop, FailureHalt, FailureRetry, FailureSkip, FailureQuarantine:
+		return policy, nil
+	}
+	return "", fmt.Errorf("failure policy '%s' not found", str)
+}
+
+// handlerCtx is the handler running in the snapshot. It is
+// used to locate the errors of the handlers.
+type handlerCtx struct {
+	tracker   string
+	txHash    web3.Hash
+	logIndex  uint64
+	address   web3.Address
+	blockTick bool
+
+	// position of the event (or call) in the action
+	event int
+	call  int
+}
+
+func eventHandlerCtx(tracker string, evnt *proto.Event, indx int) *handlerCtx {
+	return &handlerCtx{
+		tracker:  tracker,
+		txHash:   web3.HexToHash(evnt.TxHash),
+		logIndex: evnt.LogIndex,
+		address:  web3.HexToAddress(evnt.Address),
+		event:    indx,
+		call:     -1,
+	}
+}
+
+func callHandlerCtx(tracker string, call *Call, indx int) *handlerCtx {
+	return &handlerCtx{
+		tracker: tracker,
+		txHash:  call.TxHash,
+		address: call.To,
+		event:   -1,
+		call:    indx,
+	}
+}
+
+// locate fills the error with the block and the handler that failed
+func (s *Snapshot) locate(evnt *ErrorEvent) {
+	evnt.Block = s.block
+	if h := s.handler; h != nil {
+		evnt.Tracker = h.tracker
+		evnt.TxHash = h.txHash
+		evnt.LogIndex = h.logIndex
+		evnt.Address = h.address
+		evnt.handler = h
+	}
+}
+
+// Skip removes from the action the event (or call) that caused the error
+// so that the action can be processed again without it. It returns false
+// if the error is not caused by a single event, call or block tick.
+func (a *Action) Skip(evnt *ErrorEvent) bool {
+	h := evnt.handler
+	if h == nil {
+		return false
+	}
+	if h.blockTick {
+		a.BlockTick = false
+		return true
+	}
+	if h.event >= 0 && h.event < len(a.Events) {
+		a.Events = append(a.Events[:h.event], a.Events[h.event+1:]...)
+		if h.event < len(a.Templates) {
+			a.Templates = append(a.Templates[:h.event], a.Templates[h.event+1:]...)
+		}
+		return true
+	}
+	if h.call >= 0 && h.call < len(a.Calls) {
+		a.Calls = append(a.Calls[:h.call], a.Calls[h.call+1:]...)
+		return true
+	}
+	return false
+}
+
+// RemoveAddress removes from the action the events emitted by the
+// contract and the calls to the contract. It returns the number of
+// events and calls removed.
+func (a *Action) RemoveAddress(addr web3.Address) int {
+	count := 0
+
+	for i := len(a.Events) - 1; i >= 0; i-- {
+		if web3.HexToAddress(a.Events[i].Address) == addr {
+			a.Events = append(a.Events[:i], a.Events[i+1:]...)
+			if i < len(a.Templates) {
+				a.Templates = append(a.Templates[:i], a.Templates[i+1:]...)
+			}
+			count++
+		}
+	}
+
+	calls := []*Call{}
+	for _, call := range a.Calls {
+		if call.To == addr {
+			count++
+			continue
+		}
+		calls = append(calls, call)
+	}
+	a.Calls = calls
+	return count
+}
@@ -0,0 +1,167 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/eth-indexer/indexer/proto"
+	"github.com/umbracle/go-web3"
+	"github.com/umbracle/go-web3/abi"
+	gproto "google.golang.org/protobuf/proto"
+)
+
+func TestFailure_LocateAndSkip(t *testing.T) {
+	event := abi.MustNewEvent("event Ping()")
+
+	handled := []uint64{}
+	p := &Provider{
+		Trackers: []*Tracker{
+			{
+				Type: event,
+				Handler: func(req *HandlerReq) {
+					if req.Evnt.LogIndex == 1 {
+						// the table does not exist
+						req.Get("unknown", "a")
+					}
+					handled = append(handled, req.Evnt.LogIndex)
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	addrA, addrB := web3.Address{0x1}, web3.Address{0x2}
+
+	act := &Action{
+		BlockNum: 5,
+	}
+	for indx, addr := range []web3.Address{addrA, addrB, addrA} {
+		log := &web3.Log{
+			LogIndex:        uint64(indx),
+			TransactionHash: web3.Hash{byte(indx)},
+			BlockNumber:     5,
+			Address:         addr,
+			Topics:          []web3.Hash{event.ID()},
+		}
+		act.Events = append(act.Events, proto.Event{})
+		gproto.Merge(&act.Events[indx], proto.DecodeEvent(log))
+	}
+
+	_, evntErr := p.Process(act)
+	assert.NotNil(t, evntErr)
+	assert.Equal(t, ErrorEventSchemaNotFound, evntErr.Type)
+	assert.Equal(t, uint64(5), evntErr.Block)
+	assert.Equal(t, uint64(1), evntErr.LogIndex)
+	assert.Equal(t, web3.Hash{0x1}, evntErr.TxHash)
+	assert.Equal(t, addrB, evntErr.Address)
+	assert.Equal(t, "Ping", evntErr.Tracker)
+
+	// process the action again without the event that failed
+	assert.True(t, act.Skip(evntErr))
+	assert.Len(t, act.Events, 2)
+
+	handled = handled[:0]
+	_, evntErr = p.Process(act)
+	assert.Nil(t, evntErr)
+	assert.Equal(t, []uint64{0, 2}, handled)
+
+	// remove the events of a quarantined contract
+	assert.Equal(t, 2, act.RemoveAddress(addrA))
+	assert.Len(t, act.Events, 0)
+}
+
+func TestFailure_BlockTick(t *testing.T) {
+	p := &Provider{
+		BlockTrackers: []*BlockTracker{
+			{
+				Interval: 10,
+				Handler: func(req *HandlerReq) {
+					panic("failed")
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	act := &Action{
+		BlockNum:  10,
+		BlockTick: true,
+	}
+	_, evntErr := p.Process(act)
+	assert.NotNil(t, evntErr)
+	assert.Equal(t, ErrorEventPanic, evntErr.Type)
+	assert.Equal(t, "blocks-10", evntErr.Tracker)
+
+	assert.True(t, act.Skip(evntErr))
+	assert.False(t, act.BlockTick)
+}
+
+func TestFailure_SkipTemplates(t *testing.T) {
+	event := abi.MustNewEvent("event Ping()")
+
+	handled := map[string][]uint64{}
+	handler := func(name string) func(req *HandlerReq) {
+		return func(req *HandlerReq) {
+			if req.Evnt.LogIndex == 3 {
+				panic("failed")
+			}
+			handled[name] = append(handled[name], req.Evnt.LogIndex)
+		}
+	}
+	p := &Provider{
+		Trackers: []*Tracker{
+			{Type: event, Handler: handler("")},
+		},
+		Templates: map[string]*Template{
+			"pair": {
+				Trackers: []*Tracker{
+					{Type: event, Handler: handler("pair")},
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	// the events of a block replayed from the archive come from
+	// the static trackers and from the data sources of the template
+	act := &Action{
+		BlockNum:  5,
+		Templates: []string{"", "pair", "", "pair"},
+	}
+	for indx := 0; indx < 4; indx++ {
+		log := &web3.Log{
+			LogIndex:        uint64(indx),
+			TransactionHash: web3.Hash{byte(indx)},
+			BlockNumber:     5,
+			Address:         web3.Address{byte(indx)},
+			Topics:          []web3.Hash{event.ID()},
+		}
+		act.Events = append(act.Events, proto.Event{})
+		gproto.Merge(&act.Events[indx], proto.DecodeEvent(log))
+	}
+
+	_, evntErr := p.Process(act)
+	assert.NotNil(t, evntErr)
+	assert.Equal(t, uint64(3), evntErr.LogIndex)
+
+	assert.True(t, act.Skip(evntErr))
+	assert.Equal(t, []string{"", "pair", ""}, act.Templates)
+
+	handled = map[string][]uint64{}
+	_, evntErr = p.Process(act)
+	assert.Nil(t, evntErr)
+	assert.Equal(t, []uint64{0, 2}, handled[""])
+	assert.Equal(t, []uint64{1}, handled["pair"])
+
+	assert.Equal(t, 1, act.RemoveAddress(web3.Address{0x1}))
+	assert.Equal(t, []string{"", ""}, act.Templates)
+}
+
+func TestFailure_ParsePolicy(t *testing.T) {
+	policy, err := ParseFailurePolicy("skip")
+	assert.NoError(t, err)
+	assert.Equal(t, FailureSkip, policy)
+
+	_, err = ParseFailurePolicy("other")
+	assert.Error(t, err)
+}
@@ -1,12 +1,91 @@
 package sdk
 
-import "github.com/umbracle/go-web3"
+import (
+	"fmt"
+
+	"github.com/umbracle/go-web3"
+)
 
 // filters of data
 
+// Filter describes the sources of events of a provider
+type Filter interface {
+	GetSources() []*Source
+}
+
+// Source is a set of contracts tracked together since the same block
+type Source struct {
+	// Name identifies the source, it must be unique in the provider
+	Name string
+
+	// Address is the list of contracts that emit the logs
+	Address []web3.Address
+
+	// To is the list of contracts the transactions are sent to
+	To []web3.Address
+
+	// Topics is the list of event topics to track (any of them).
+	// If empty, all the logs are tracked.
+	Topics []web3.Hash
+
+	// TrackerTopics restricts the logs to the events handled by the
+	// trackers of the provider if Topics is empty.
+	TrackerTopics bool
+
+	StartBlock uint64
+}
+
 // exact address filter
 type FilterByAddr struct {
 	FromAddr   web3.Address
 	ToAddr     web3.Address
 	StartBlock uint64
 }
+
+// GetSources implements the Filter interface
+func (f *FilterByAddr) GetSources() []*Source {
+	source := &Source{
+		Name:       "-",
+		StartBlock: f.StartBlock,
+	}
+	if f.FromAddr != zeroAddr {
+		source.Address = []web3.Address{f.FromAddr}
+	}
+	if f.ToAddr != zeroAddr {
+		source.To = []web3.Address{f.ToAddr}
+	}
+	return []*Source{source}
+}
+
+// FilterBySources is a filter with several sources, each one
+// with its own addresses, topics and start block
+type FilterBySources struct {
+	Sources []*Source
+}
+
+// GetSources implements the Filter interface
+func (f *FilterBySources) GetSources() []*Source {
+	return f.Sources
+}
+
+func (s *Source) copy() *Source {
+	ss := new(Source)
+	*ss = *s
+	return ss
+}
+
+var zeroAddr = web3.Address{}
+
+func validateSources(sources []*Source) error {
+	names := map[string]struct{}{}
+	for _, source := range sources {
+		if source.Name == "" {
+			return fmt.Errorf("source without name")
+		}
+		if _, ok := names[source.Name]; ok {
+			return fmt.Errorf("source '%s' is duplicated", source.Name)
+		}
+		names[source.Name] = struct{}{}
+	}
+	return nil
+}
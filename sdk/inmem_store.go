@@ -26,3 +26,11 @@ func (i *inmemStore) add(k string, val *Obj2) {
 	// it is assumed that this is locked
 	i.cache.Add(k, val)
 }
+
+func (i *inmemStore) remove(k string) {
+	i.cache.Remove(k)
+}
+
+func (i *inmemStore) purge() {
+	i.cache.Purge()
+}
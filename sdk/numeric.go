@@ -0,0 +1,71 @@
+package sdk
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+var (
+	// maxUint256 is the highest value of a TypeUint field
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	// minInt256 and maxInt256 are the bounds of a TypeBigInt field
+	minInt256 = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 255))
+	maxInt256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1))
+
+	// minInt64 and maxInt64 are the bounds of a TypeInt field
+	minInt64 = big.NewInt(math.MinInt64)
+	maxInt64 = big.NewInt(math.MaxInt64)
+)
+
+// intBounds returns the range of values of an integer field type
+func intBounds(typ FieldType) (min, max *big.Int, ok bool) {
+	switch typ {
+	case TypeUint:
+		return new(big.Int), maxUint256, true
+	case TypeBigInt:
+		return minInt256, maxInt256, true
+	case TypeInt:
+		return minInt64, maxInt64, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// checkBounds returns an error if the value is out of the range of the field type
+func checkBounds(typ FieldType, val *big.Int) error {
+	if val == nil {
+		return fmt.Errorf("is empty")
+	}
+	min, max, ok := intBounds(typ)
+	if !ok {
+		return nil
+	}
+	if val.Cmp(min) < 0 {
+		return fmt.Errorf("value %s is lower than the minimum %s of %s", val, min, typ)
+	}
+	if val.Cmp(max) > 0 {
+		return fmt.Errorf("value %s is higher than the maximum %s of %s", val, max, typ)
+	}
+	return nil
+}
+
+// toBigInt converts an integer to a big integer
+func toBigInt(v interface{}) (*big.Int, bool) {
+	switch obj := v.(type) {
+	case *big.Int:
+		if obj == nil {
+			return nil, false
+		}
+		return obj, true
+	case uint64:
+		return new(big.Int).SetUint64(obj), true
+	case int64:
+		return big.NewInt(obj), true
+	case int:
+		return big.NewInt(int64(obj)), true
+	default:
+		return nil, false
+	}
+}
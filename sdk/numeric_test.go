@@ -0,0 +1,100 @@
+package sdk
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumeric_AddSub(t *testing.T) {
+	var handler func(req *HandlerReq)
+
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"balance": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "id", Type: TypeAddress, ID: true},
+						{Name: "amount", Type: TypeUint},
+						{Name: "delta", Type: TypeInt},
+					},
+				},
+			},
+		},
+		BlockTrackers: []*BlockTracker{
+			{
+				Interval: 1,
+				Handler: func(req *HandlerReq) {
+					handler(req)
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	process := func(num uint64, fn func(req *HandlerReq)) *ErrorEvent {
+		handler = fn
+		_, evntErr := p.Process(&Action{BlockNum: num, BlockTick: true})
+		return evntErr
+	}
+
+	half := new(big.Int).Lsh(big.NewInt(1), 255)
+
+	// values higher than 64 bits
+	var amount *big.Int
+	assert.Nil(t, process(1, func(req *HandlerReq) {
+		obj := req.Get("balance", "a")
+		obj.Add("amount", half)
+		obj.Add("amount", new(big.Int).Sub(half, big.NewInt(1)))
+		obj.Sub("amount", uint64(1))
+		obj.Sub("delta", 5)
+		amount = obj.Get("amount").(*big.Int)
+	}))
+	assert.Equal(t, new(big.Int).Sub(maxUint256, big.NewInt(1)), amount)
+
+	// overflow of the uint256
+	evntErr := process(2, func(req *HandlerReq) {
+		obj := req.Get("balance", "a")
+		obj.Add("amount", uint64(2))
+	})
+	assert.NotNil(t, evntErr)
+	assert.Equal(t, ErrorEventOverflow, evntErr.Type)
+
+	// underflow of the uint256
+	evntErr = process(3, func(req *HandlerReq) {
+		obj := req.Get("balance", "b")
+		obj.Sub("amount", uint64(1))
+	})
+	assert.NotNil(t, evntErr)
+	assert.Equal(t, ErrorEventUnderflow, evntErr.Type)
+
+	// the int fields are int64
+	var delta int64
+	assert.Nil(t, process(4, func(req *HandlerReq) {
+		obj := req.Get("balance", "c")
+		obj.Sub("delta", int64(5))
+		delta = obj.Get("delta").(int64)
+	}))
+	assert.Equal(t, int64(-5), delta)
+}
+
+func TestNumeric_Bounds(t *testing.T) {
+	f := &Field{Name: "a", Type: TypeUint}
+
+	enc, err := f.Encode(maxUint256)
+	assert.NoError(t, err)
+
+	dec, err := f.Decode(enc)
+	assert.NoError(t, err)
+	assert.Equal(t, maxUint256, dec)
+
+	_, err = f.Encode(new(big.Int).Add(maxUint256, big.NewInt(1)))
+	assert.Error(t, err)
+
+	_, err = f.Encode(big.NewInt(-1))
+	assert.Error(t, err)
+
+	_, err = f.Decode("-1")
+	assert.Error(t, err)
+}
@@ -2,13 +2,11 @@ package sdk
 
 import (
 	"fmt"
-	"strconv"
 
 	"github.com/umbracle/eth-indexer/indexer/proto"
 	protosdk "github.com/umbracle/eth-indexer/sdk/proto"
 	"github.com/umbracle/go-web3"
 	"github.com/umbracle/go-web3/abi"
-	"github.com/umbracle/go-web3/jsonrpc"
 )
 
 var _ Backend = &Provider{}
@@ -18,18 +16,45 @@ type HandlerReq struct {
 	Evnt *proto.Event
 	Vals map[string]interface{}
 
+	// Call is the call that triggered a call tracker
+	Call *Call
+
+	// Outputs are the decoded outputs of the call (if available)
+	Outputs map[string]interface{}
+
 	Indx   int
 	Action *Action
 }
 
 type Handler func(HandlerReq)
 
+// Block returns the header of the block that includes the event
+func (h *HandlerReq) Block() *Block {
+	return h.Action.Block
+}
+
+// Track starts to track the contract at 'addr' with the trackers of the template.
+// The contract is tracked from the current block.
+func (h *HandlerReq) Track(template string, addr web3.Address) {
+	if _, ok := h.provider.Templates[template]; !ok {
+		h.finish(&ErrorEvent{
+			Type: ErrorEventTemplateNotFound,
+			Err:  fmt.Errorf("template '%s' is not defined in the provider", template),
+		})
+	}
+	h.sources = append(h.sources, &DataSource{
+		Template:   template,
+		Address:    addr,
+		StartBlock: h.Action.BlockNum,
+	})
+}
+
 type Tracker struct {
 	Type    *abi.Event
 	Handler func(*HandlerReq)
 }
 
-type ResourceInit func(addr web3.Address, provider *jsonrpc.Client, obj *Obj2) error
+type ResourceInit func(addr web3.Address, provider Client, obj *Obj2) error
 
 type Resource struct {
 	Name      string
@@ -38,16 +63,50 @@ type Resource struct {
 	Init      ResourceInit
 }
 
+// Template is a set of trackers for contracts whose address is only
+// known at runtime (i.e. contracts created by a factory). The contracts
+// are tracked with HandlerReq.Track.
+type Template struct {
+	Trackers []*Tracker
+}
+
+// DataSource is a contract tracked with the trackers of a template
+type DataSource struct {
+	Template   string
+	Address    web3.Address
+	StartBlock uint64
+}
+
 type Provider struct {
 	Resources map[string]*Resource
 	Snapshots map[string]*Snapshot2
 	Trackers  []*Tracker
-	Filter    *FilterByAddr
+
+	// Candles are the OHLC candles of the decimal fields
+	Candles map[string]*Candle
+
+	Templates map[string]*Template
+	Filter    Filter
+
+	// CallTrackers are the handlers for the calls
+	// to the contracts of the filter
+	CallTrackers []*CallTracker
+
+	// BlockTrackers are the handlers that run at fixed block intervals
+	BlockTrackers []*BlockTracker
+
+	// StoreTransactions stores the transactions of the
+	// events in the transactions table
+	StoreTransactions bool
+
+	// FailurePolicy is how the indexer handles the errors
+	// of the handlers. It defaults to FailureStop.
+	FailurePolicy FailurePolicy
 
 	// state resolver
 	resolver StateResolver
 
-	client *jsonrpc.Client
+	client Client
 
 	// the list of all the schemas of this provider
 	schemas map[string]*Table
@@ -58,15 +117,41 @@ type Provider struct {
 	snap *Snapshot
 }
 
-func (p *Provider) GetFilter() *FilterByAddr {
+func (p *Provider) GetFilter() Filter {
 	return p.Filter
 }
 
+// GetSources returns the sources of the filter with the topics
+// of the trackers resolved
+func (p *Provider) GetSources() []*Source {
+	if p.Filter == nil {
+		return nil
+	}
+	res := []*Source{}
+	for _, source := range p.Filter.GetSources() {
+		if source.TrackerTopics && len(source.Topics) == 0 {
+			source = source.copy()
+			source.Topics = p.trackerTopics()
+		}
+		res = append(res, source)
+	}
+	return res
+}
+
+// trackerTopics returns the topics of the events handled by the trackers
+func (p *Provider) trackerTopics() []web3.Hash {
+	topics := []web3.Hash{}
+	for _, t := range p.Trackers {
+		topics = append(topics, t.Type.ID())
+	}
+	return topics
+}
+
 func (p *Provider) SetStateResolver(resolver StateResolver) {
 	p.resolver = resolver
 }
 
-func (p *Provider) SetClient(client *jsonrpc.Client) {
+func (p *Provider) SetClient(client Client) {
 	p.client = client
 }
 
@@ -94,16 +179,46 @@ func (p *Provider) Init() error {
 	p.schemas = map[string]*Table{}
 	p.indexers = []indexer{}
 
+	// report all the problems of the definitions before
+	// the schemas are built and the tables created
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
 	// build the schemas for the resources
 	for name, c := range p.Resources {
 		p.addSchema(name, c.Schema)
 	}
+	for _, sch := range p.schemas {
+		for _, field := range sch.Fields {
+			if ref := field.References; ref != nil && ref.Field == "" {
+				// reference the id of the table
+				ref.Field = p.schemas[ref.Table].getIDS()[0].Name
+			}
+		}
+		for _, index := range sch.Indexes {
+			if index.Name == "" {
+				index.Name = indexName(sch.Name, index)
+			}
+		}
+	}
 
 	// parse the event trackers after the snapshots since we want
 	// all the snapshot indexers at the end of the execution
 	for _, t := range p.Trackers {
 		p.indexers = append(p.indexers, &trackerIndexer22{tracker: t})
 	}
+	for name, template := range p.Templates {
+		for _, t := range template.Trackers {
+			p.indexers = append(p.indexers, &trackerIndexer22{tracker: t, template: name})
+		}
+	}
+	for _, t := range p.CallTrackers {
+		p.indexers = append(p.indexers, &callIndexer{tracker: t})
+	}
+	for _, t := range p.BlockTrackers {
+		p.indexers = append(p.indexers, &blockIndexer{tracker: t})
+	}
 
 	// build schemas for snapshots
 	for name, def := range p.Snapshots {
@@ -111,6 +226,11 @@ func (p *Provider) Init() error {
 			return err
 		}
 	}
+	for name, def := range p.Candles {
+		if err := p.buildCandle(name, def); err != nil {
+			return err
+		}
+	}
 
 	p.snap = newSnapshot()
 	p.snap.provider = p
@@ -119,9 +239,18 @@ func (p *Provider) Init() error {
 }
 
 type Snapshot2 struct {
-	Table     string
-	Index     []string
-	SplitFunc SplitFunc
+	Table string
+
+	// Index are the fields of the table whose last value
+	// in the window is stored in the snapshot
+	Index []string
+
+	// Aggregates are the aggregations of the changes of the
+	// fields of the table in the window
+	Aggregates []*Aggregate
+
+	// Split divides the blocks in windows (default one window per block)
+	Split *Split
 }
 
 func (p *Provider) buildSnapshot(snapshotName string, snapshot *Snapshot2) error {
@@ -136,36 +265,44 @@ func (p *Provider) buildSnapshot(snapshotName string, snapshot *Snapshot2) error
 		Fields: []*Field{},
 	}
 
-	fmt.Println("-- snap schema --")
-	fmt.Println(snapshostSchema)
-
-	// get the ids of the table since those work as anchors
-	idFields := table.getIDS()
-	snapshostSchema.Fields = append(snapshostSchema.Fields, idFields...)
+	// get the ids of the table since those work as anchors. The fields
+	// are copied without the defaults and the references of the table.
+	for _, field := range table.getIDS() {
+		snapshostSchema.Fields = append(snapshostSchema.Fields, &Field{Name: field.Name, Type: field.Type, ID: true})
+	}
 
 	// get the index fields
 	for _, fieldName := range snapshot.Index {
 		if field := table.getField(fieldName); field != nil {
-			snapshostSchema.Fields = append(snapshostSchema.Fields, field)
+			snapshostSchema.Fields = append(snapshostSchema.Fields, &Field{Name: field.Name, Type: field.Type})
 		} else {
 			return fmt.Errorf("field '%s' does not exists", fieldName)
 		}
 	}
 
-	// append the split separator, its an ID as well in order to make
-	// the index entry unique
-	blockField := &Field{
-		Name: "block",
-		ID:   true,
-		Type: TypeAddress,
+	// get the columns of the aggregates
+	snapshostSchema.Fields = append(snapshostSchema.Fields, aggregateFields(table, snapshot.Aggregates)...)
+
+	// the tombstone of the objects removed in the window
+	snapshostSchema.Fields = append(snapshostSchema.Fields, &Field{Name: snapshotRemovedColumn, Type: TypeBool, Default: false})
+
+	// append the start of the window, its an ID as well in order
+	// to make the index entry unique
+	split := snapshot.getSplit()
+	splitField := &Field{
+		Name:    split.Column,
+		ID:      true,
+		Type:    split.Type,
+		Convert: split.Convert,
 	}
-	snapshostSchema.Fields = append(snapshostSchema.Fields, blockField)
+	snapshostSchema.Fields = append(snapshostSchema.Fields, splitField)
 
 	p.addSchema(snapshotName, snapshostSchema)
 
 	process := &snapIndexer22{
 		snapshot:     snapshot,
 		snapshotName: snapshotName,
+		table:        table,
 		schema:       snapshostSchema,
 	}
 	p.indexers = append(p.indexers, process)
@@ -174,12 +311,25 @@ func (p *Provider) buildSnapshot(snapshotName string, snapshot *Snapshot2) error
 
 func (p *Provider) Process(act *Action) ([]*protosdk.Diff, *ErrorEvent) {
 	// start the snapshot
+	p.snap.err = nil
+	p.snap.handler = nil
 	p.snap.block = act.BlockNum
+	p.snap.sources = nil
+	p.snap.txns = map[web3.Hash]*web3.Transaction{}
+	p.snap.receipts = map[web3.Hash]*web3.Receipt{}
 
 	// loop the indexers
 	closeCh := make(chan struct{})
 	go func() {
 		defer func() {
+			// a panic in a handler stops the provider but not the process
+			if r := recover(); r != nil {
+				p.snap.err = &ErrorEvent{
+					Type: ErrorEventPanic,
+					Err:  fmt.Errorf("%v", r),
+				}
+				p.snap.locate(p.snap.err)
+			}
 			close(closeCh)
 		}()
 		for _, ii := range p.indexers {
@@ -194,11 +344,32 @@ func (p *Provider) Process(act *Action) ([]*protosdk.Diff, *ErrorEvent) {
 
 	<-closeCh
 
+	if evntErr := p.snap.err; evntErr != nil {
+		// discard the changes of the handlers, the objects
+		// in the cache might be modified
+		p.Invalidate()
+		return nil, evntErr
+	}
+
 	// save the snapshot to generate the diffs
 	diffs := p.snap.save()
 	p.snap.reset()
 
-	return diffs, p.snap.err
+	return diffs, nil
+}
+
+// DataSources returns the data sources created by the handlers
+// during the last call to Process.
+func (p *Provider) DataSources() []*DataSource {
+	return p.snap.sources
+}
+
+// Invalidate drops all the objects cached in memory. It has to be called
+// whenever the persisted state is reverted (i.e. after a reorg) so that
+// the handlers do not read values that are not valid anymore.
+func (p *Provider) Invalidate() {
+	p.snap.inmemStore.purge()
+	p.snap.reset()
 }
 
 func (p *Provider) GetSchemas() GetSchemasResponse {
@@ -209,25 +380,17 @@ func (p *Provider) GetSchemas() GetSchemasResponse {
 	return resp
 }
 
-type SplitFunc func(block uint64) string
-
-func BlockSplitFunc(blockSize uint64) SplitFunc {
-	return func(block uint64) string {
-		return strconv.Itoa(int(block / blockSize))
-	}
-}
-
-func NoSplitFunc(block uint64) SplitFunc {
-	// generates one snapshot per each block
-	return BlockSplitFunc(1)
-}
-
 type trackerIndexer22 struct {
-	tracker *Tracker
+	tracker  *Tracker
+	template string
 }
 
 func (s *trackerIndexer22) Process(ac *Action, i *Snapshot) error {
 	for indx, evnt := range ac.Events {
+		if ac.eventTemplate(indx) != s.template {
+			// the event comes from another data source
+			continue
+		}
 		if evnt.TopicID == s.tracker.Type.ID().String() {
 			log, err := evnt.ToLog()
 			if err != nil {
@@ -244,57 +407,121 @@ func (s *trackerIndexer22) Process(ac *Action, i *Snapshot) error {
 				Action:   ac,
 				Indx:     indx,
 			}
+			i.handler = eventHandlerCtx(s.name(), &evnt, indx)
 			s.tracker.Handler(req)
+			i.handler = nil
 		}
 	}
 	return nil
 }
 
+// name is the name of the tracker, prefixed with the template if any
+func (s *trackerIndexer22) name() string {
+	if s.template != "" {
+		return s.template + "." + s.tracker.Type.Name
+	}
+	return s.tracker.Type.Name
+}
+
+// snapshotRemovedColumn is the column of the snapshots that records
+// whether the object was removed in the window
+const snapshotRemovedColumn = "removed"
+
 type snapIndexer22 struct {
 	snapshot     *Snapshot2
 	snapshotName string
+	table        *Table
 	schema       *Table
 }
 
 func (s *snapIndexer22) Process(ac *Action, i *Snapshot) error {
-	block := i.block
+	header := ac.Block
+	if header == nil {
+		header = &Block{Number: ac.BlockNum}
+	}
+	split := s.snapshot.getSplit()
+	if split.Type == TypeTimestamp && header.Timestamp == 0 {
+		return fmt.Errorf("snapshot '%s' requires the timestamp of the block %d", s.snapshotName, ac.BlockNum)
+	}
+	bucket := split.Func(header)
 
-	for _, obj := range i.trackedObjs {
+	// the entries of the snapshot are tracked while looping
+	tracked := append([]*Obj2{}, i.trackedOrder...)
+	for _, obj := range tracked {
 		if obj.table != s.snapshot.Table {
-			return nil
+			continue
 		}
-
-		indexColName := s.snapshot.Index[0]
-		val, ok := obj.hasChanged(indexColName)
-		if !ok {
-			return nil
-		}
-
-		var numKey string
-		if s.snapshot.SplitFunc == nil {
-			// store this entry for sure so use the blcoka s index
-			numKey = strconv.Itoa(int(block))
-		} else {
-			numKey = s.snapshot.SplitFunc(block)
+		if obj.removed {
+			if obj.created {
+				// the object never existed out of the block
+				continue
+			}
+		} else if !s.hasChanges(obj) {
+			continue
 		}
 
+		// the keys in the order of the id fields
 		kk := []interface{}{}
-		for name, v := range obj.key {
-			// we need to encode it not as string but as his own type
-			val, err := s.schema.getField(name).Decode(v)
+		for _, field := range s.table.getIDS() {
+			val, err := field.Decode(obj.key[field.Name])
 			if err != nil {
 				return err
 			}
 			kk = append(kk, val)
 		}
+		kk = append(kk, bucket)
 
-		kk = append(kk, numKey)
-
-		index := i.Get(s.snapshotName, kk...)
-		index.Set(indexColName, val)
+		entry := i.Get(s.snapshotName, kk...)
+		if obj.removed {
+			entry.Set(snapshotRemovedColumn, true)
+			continue
+		}
+		if entry.Get(snapshotRemovedColumn).(bool) {
+			// the object was created again in the window
+			entry.Set(snapshotRemovedColumn, false)
+		}
+		for _, name := range s.snapshot.Index {
+			if val, ok := obj.hasChanged(name); ok {
+				entry.Set(name, s.decode(i, name, val))
+			}
+		}
+		for _, a := range s.snapshot.Aggregates {
+			val, ok := obj.hasChanged(a.Field)
+			if !ok {
+				continue
+			}
+			// the value before the block (if any)
+			a.aggregate(entry, s.table.getField(a.Field), obj.vals[a.Field], val)
+		}
 	}
 	return nil
 }
 
+// hasChanges returns true if any of the fields of the snapshot changed in the object
+func (s *snapIndexer22) hasChanges(obj *Obj2) bool {
+	for _, name := range s.snapshot.Index {
+		if _, ok := obj.hasChanged(name); ok {
+			return true
+		}
+	}
+	for _, a := range s.snapshot.Aggregates {
+		if _, ok := obj.hasChanged(a.Field); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *snapIndexer22) decode(i *Snapshot, name, val string) interface{} {
+	v, err := s.table.getField(name).Decode(val)
+	if err != nil {
+		i.finish(&ErrorEvent{
+			Type: ErrorEventGetDecode,
+			Err:  fmt.Errorf("failed to decode %s: %v", name, err),
+		})
+	}
+	return v
+}
+
 // default fields for all the items
 var extraFields = []*Field{}
@@ -1,10 +1,13 @@
 package sdk
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math/big"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/umbracle/go-web3"
 )
@@ -15,19 +18,61 @@ const (
 	TypeAddress FieldType = iota + 1
 	TypeUint
 	TypeDecimal
+	TypeString
+	TypeBool
+	TypeBytes
+	TypeHash
+	TypeInt
+	TypeTimestamp
+	TypeBigInt
 )
 
+func (t FieldType) String() string {
+	switch t {
+	case TypeAddress:
+		return "address"
+	case TypeUint:
+		return "uint"
+	case TypeDecimal:
+		return "decimal"
+	case TypeString:
+		return "string"
+	case TypeBool:
+		return "bool"
+	case TypeBytes:
+		return "bytes"
+	case TypeHash:
+		return "hash"
+	case TypeInt:
+		return "int"
+	case TypeTimestamp:
+		return "timestamp"
+	case TypeBigInt:
+		return "bigint"
+	default:
+		return fmt.Sprintf("FieldType(%d)", int(t))
+	}
+}
+
+// bytesPrefix is the prefix of the encoded bytes. It is the hex
+// format of the bytea type in Postgresql so that the values are
+// stored without any conversion.
+const bytesPrefix = "\\x"
+
 func (f *Field) Decode(val string) (interface{}, error) {
 	switch f.Type {
 	case TypeAddress:
 		return val, nil
 
 	case TypeUint:
-		v, err := strconv.Atoi(val)
-		if err != nil {
+		v, ok := new(big.Int).SetString(val, 10)
+		if !ok {
 			return nil, fmt.Errorf("failed to decode uint: %v", val)
 		}
-		return uint64(v), nil
+		if err := checkBounds(TypeUint, v); err != nil {
+			return nil, err
+		}
+		return v, nil
 
 	case TypeDecimal:
 		f := new(Float)
@@ -36,6 +81,47 @@ func (f *Field) Decode(val string) (interface{}, error) {
 		}
 		return f, nil
 
+	case TypeString:
+		return val, nil
+
+	case TypeBool:
+		v, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode bool: %v", val)
+		}
+		return v, nil
+
+	case TypeBytes:
+		return decodeBytes(val)
+
+	case TypeHash:
+		var hash web3.Hash
+		if err := hash.UnmarshalText([]byte(val)); err != nil {
+			return nil, fmt.Errorf("failed to decode hash: %v", val)
+		}
+		return hash, nil
+
+	case TypeInt:
+		v, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode int: %v", val)
+		}
+		return v, nil
+
+	case TypeTimestamp:
+		v, err := time.Parse(time.RFC3339Nano, val)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode timestamp: %v", val)
+		}
+		return v.UTC(), nil
+
+	case TypeBigInt:
+		v, ok := new(big.Int).SetString(val, 10)
+		if !ok {
+			return nil, fmt.Errorf("failed to decode bigint: %v", val)
+		}
+		return v, nil
+
 	default:
 		panic(fmt.Sprintf("Decode type not found %v", f.Type))
 	}
@@ -62,9 +148,12 @@ func (f *Field) Encode(raw interface{}) (string, error) {
 		var val string
 		switch obj := raw.(type) {
 		case *big.Int:
+			if err := checkBounds(TypeUint, obj); err != nil {
+				return "", fmt.Errorf("field %s: %v", f.Name, err)
+			}
 			val = obj.String()
 		case uint64:
-			val = strconv.Itoa(int(obj))
+			val = strconv.FormatUint(obj, 10)
 		default:
 			return "", fmt.Errorf("%s bad 2 %s", f.Name, reflect.TypeOf(raw))
 		}
@@ -80,9 +169,94 @@ func (f *Field) Encode(raw interface{}) (string, error) {
 		}
 		return val, nil
 
+	case TypeString:
+		switch obj := raw.(type) {
+		case string:
+			return obj, nil
+		case fmt.Stringer:
+			return obj.String(), nil
+		}
+
+	case TypeBool:
+		if obj, ok := raw.(bool); ok {
+			return strconv.FormatBool(obj), nil
+		}
+
+	case TypeBytes:
+		switch obj := raw.(type) {
+		case []byte:
+			return bytesPrefix + hex.EncodeToString(obj), nil
+		case string:
+			// a hex string
+			buf, err := decodeBytes(obj)
+			if err != nil {
+				return "", err
+			}
+			return bytesPrefix + hex.EncodeToString(buf), nil
+		}
+
+	case TypeHash:
+		switch obj := raw.(type) {
+		case web3.Hash:
+			return obj.String(), nil
+		case string:
+			var hash web3.Hash
+			if err := hash.UnmarshalText([]byte(obj)); err != nil {
+				return "", fmt.Errorf("field %s: incorrect hash %s", f.Name, obj)
+			}
+			return hash.String(), nil
+		}
+
+	case TypeInt:
+		switch obj := raw.(type) {
+		case int64:
+			return strconv.FormatInt(obj, 10), nil
+		case int:
+			return strconv.FormatInt(int64(obj), 10), nil
+		case int32:
+			return strconv.FormatInt(int64(obj), 10), nil
+		}
+
+	case TypeTimestamp:
+		switch obj := raw.(type) {
+		case time.Time:
+			return obj.UTC().Format(time.RFC3339Nano), nil
+		case uint64:
+			// unix timestamp in seconds (i.e. the timestamp of a block)
+			return time.Unix(int64(obj), 0).UTC().Format(time.RFC3339Nano), nil
+		}
+
+	case TypeBigInt:
+		switch obj := raw.(type) {
+		case *big.Int:
+			if err := checkBounds(TypeBigInt, obj); err != nil {
+				return "", fmt.Errorf("field %s: %v", f.Name, err)
+			}
+			return obj.String(), nil
+		case int64:
+			return strconv.FormatInt(obj, 10), nil
+		case uint64:
+			return strconv.FormatUint(obj, 10), nil
+		}
+
 	default:
 		panic(fmt.Sprintf("Decode type not found %v", f.Type))
 	}
+	return "", fmt.Errorf("field %s of type %s cannot encode %s", f.Name, f.Type, reflect.TypeOf(raw))
+}
+
+// decodeBytes decodes an hex string with either the 0x
+// prefix or the one of the bytea type in Postgresql
+func decodeBytes(val string) ([]byte, error) {
+	str := strings.TrimPrefix(val, bytesPrefix)
+	if len(str) == len(val) {
+		str = strings.TrimPrefix(val, "0x")
+	}
+	buf, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bytes: %v", val)
+	}
+	return buf, nil
 }
 
 type Field struct {
@@ -93,11 +267,36 @@ type Field struct {
 	Default     interface{}
 	Type        FieldType
 	Description string
+
+	// Convert converts the column created by a previous
+	// version of the schema with another type (optional)
+	Convert *Conversion
 }
 
+// Conversion changes the type of a column with an SQL expression
+type Conversion struct {
+	// From is the type of the column in Postgresql (i.e. text)
+	From string
+
+	// Using is the expression that computes the new values
+	Using string
+}
+
+// Reference is a foreign key to the ID of another table of the provider
 type Reference struct {
 	Table string
+
+	// Field is the ID field of the referenced table. It is
+	// optional if the table has a single ID field.
 	Field string
+
+	// Deferred checks the constraint when the diffs of the block
+	// are committed instead of after each insert or update
+	Deferred bool
+
+	// Reverse is the name of the list of referencing objects in the
+	// GraphQL type of the referenced table (default <table>s_<field>)
+	Reverse string
 }
 
 type Schema2 struct {
@@ -105,8 +304,50 @@ type Schema2 struct {
 }
 
 type Table struct {
-	Name   string
-	Fields []*Field
+	Name    string
+	Fields  []*Field
+	Indexes []*Index
+}
+
+// Index is a secondary index of the table to filter or sort the
+// objects by fields other than the ID
+type Index struct {
+	// Name is the name of the index (default <table>_<fields>_idx)
+	Name string
+
+	Fields []IndexField
+
+	// Where is the condition of a partial index in SQL (optional)
+	Where string
+}
+
+// IndexField is a field of an index and its order
+type IndexField struct {
+	Name string
+	Desc bool
+}
+
+// Asc is a field of an index in ascending order
+func Asc(name string) IndexField {
+	return IndexField{Name: name}
+}
+
+// Desc is a field of an index in descending order
+func Desc(name string) IndexField {
+	return IndexField{Name: name, Desc: true}
+}
+
+// indexName returns the default name of the index
+func indexName(table string, index *Index) string {
+	parts := []string{table}
+	for _, f := range index.Fields {
+		parts = append(parts, f.Name)
+	}
+	name := strings.ToLower(strings.Join(parts, "_"))
+	if len(name) > maxIdentifierLen-len("_idx") {
+		name = name[:maxIdentifierLen-len("_idx")]
+	}
+	return name + "_idx"
 }
 
 func (t *Table) getField(id string) *Field {
@@ -0,0 +1,61 @@
+package sdk
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/go-web3"
+)
+
+func TestField_EncodeDecode(t *testing.T) {
+	cases := []struct {
+		typ FieldType
+		raw interface{}
+		enc string
+		dec interface{}
+	}{
+		{TypeString, "it's a name", "it's a name", "it's a name"},
+		{TypeBool, true, "true", true},
+		{TypeBytes, []byte{0x1, 0xab}, "\\x01ab", []byte{0x1, 0xab}},
+		{TypeBytes, "0x01ab", "\\x01ab", []byte{0x1, 0xab}},
+		{TypeHash, web3.Hash{0x1}, web3.Hash{0x1}.String(), web3.Hash{0x1}},
+		{TypeInt, int64(-10), "-10", int64(-10)},
+		{TypeInt, 5, "5", int64(5)},
+		{TypeTimestamp, uint64(1600000000), "2020-09-13T12:26:40Z", time.Unix(1600000000, 0).UTC()},
+		{TypeBigInt, big.NewInt(-1), "-1", big.NewInt(-1)},
+		{TypeBigInt, uint64(10), "10", big.NewInt(10)},
+	}
+
+	for _, c := range cases {
+		f := &Field{Name: "a", Type: c.typ}
+
+		enc, err := f.Encode(c.raw)
+		assert.NoError(t, err, c.typ.String())
+		assert.Equal(t, c.enc, enc, c.typ.String())
+
+		dec, err := f.Decode(enc)
+		assert.NoError(t, err, c.typ.String())
+		assert.Equal(t, c.dec, dec, c.typ.String())
+	}
+}
+
+func TestField_EncodeBadType(t *testing.T) {
+	cases := []struct {
+		typ FieldType
+		raw interface{}
+	}{
+		{TypeBool, "true"},
+		{TypeBytes, "0xzz"},
+		{TypeHash, "0x1"},
+		{TypeInt, uint64(1)},
+		{TypeTimestamp, "now"},
+		{TypeBigInt, "1"},
+	}
+	for _, c := range cases {
+		f := &Field{Name: "a", Type: c.typ}
+		_, err := f.Encode(c.raw)
+		assert.Error(t, err, c.typ.String())
+	}
+}
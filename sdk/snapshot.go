@@ -3,13 +3,17 @@ package sdk
 import (
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"runtime"
+	"strings"
 
 	protosdk "github.com/umbracle/eth-indexer/sdk/proto"
+	"github.com/umbracle/go-web3"
 )
 
 type objErr interface {
 	finish(*ErrorEvent)
+	Get(tableName string, keyRaw ...interface{}) *Obj2
 }
 
 type Obj2 struct {
@@ -17,17 +21,33 @@ type Obj2 struct {
 	// TODO: provider interface to call finish
 	objErr  objErr
 	created bool
+	removed bool
 	id      []byte
 	table   string
 	key     map[string]string
 	vals    map[string]string
 	changes map[string]string
+
+	// sets are the values set in the block in order, the
+	// candles need the intermediate values of the fields
+	sets map[string][]string
 }
 
 func (o *Obj2) IsNew() bool {
 	return o.created
 }
 
+// Remove deletes the object at the end of the block. The object cannot be
+// modified afterwards but the handlers can create it again with Get.
+func (o *Obj2) Remove() {
+	o.removed = true
+}
+
+// IsRemoved returns true if the object was removed in the block
+func (o *Obj2) IsRemoved() bool {
+	return o.removed
+}
+
 func (o *Obj2) getField(name string) *Field {
 	for _, f := range o.schema.Fields {
 		if f.Name == name {
@@ -51,6 +71,13 @@ func (o *Obj2) hasChanged(key string) (string, bool) {
 }
 
 func (o *Obj2) Set(key string, val interface{}) {
+	if o.removed {
+		o.objErr.finish(&ErrorEvent{
+			Type: ErrorEventObjectRemoved,
+			Err:  fmt.Errorf("cannot set %s %s", o.table, key),
+		})
+	}
+
 	// convert val to a string representation
 	field := o.getField(key)
 	valStr, err := field.Encode(val)
@@ -61,6 +88,11 @@ func (o *Obj2) Set(key string, val interface{}) {
 		})
 	}
 
+	if o.sets == nil {
+		o.sets = map[string][]string{}
+	}
+	o.sets[key] = append(o.sets[key], valStr)
+
 	if raw, ok := o.vals[key]; ok {
 		if raw != val {
 			o.changes[key] = valStr
@@ -99,12 +131,29 @@ func (o *Obj2) GetOk(key string) (interface{}, bool) {
 	return val, true
 }
 
+// Ref returns the object referenced by the field or nil if the field is not
+// set. Like Get, the referenced object is created if it does not exist.
+func (o *Obj2) Ref(key string) *Obj2 {
+	field := o.getField(key)
+	if field.References == nil {
+		o.objErr.finish(&ErrorEvent{
+			Type: ErrorEventFieldBadType,
+			Err:  fmt.Errorf("field %s of table %s is not a reference", key, o.table),
+		})
+	}
+	val, ok := o.GetOk(key)
+	if !ok {
+		return nil
+	}
+	return o.objErr.Get(field.References.Table, val)
+}
+
 func (o *Obj2) expect(key string, fieldType FieldType) {
 	field := o.getField(key)
 	if field.Type != fieldType {
 		o.objErr.finish(&ErrorEvent{
 			Type: ErrorEventFieldBadType,
-			Err:  fmt.Errorf("field %s expected %d but found %d", key, fieldType, field.Type),
+			Err:  fmt.Errorf("field %s expected %s but found %s", key, fieldType, field.Type),
 		})
 	}
 }
@@ -114,36 +163,75 @@ func (o *Obj2) Incr(key string) {
 }
 
 func (o *Obj2) Sub(key string, v interface{}) {
-	var val interface{}
-
-	switch obj := v.(type) {
-	case *Float:
+	if obj, ok := v.(*Float); ok {
 		o.expect(key, TypeDecimal)
-		val = o.Get(key).(*Float).Sub(obj)
-
-	case uint64:
-		o.expect(key, TypeUint)
-		val = o.Get(key).(uint64) - obj
+		o.Set(key, o.Get(key).(*Float).Sub(obj))
+		return
 	}
-	o.Set(key, val)
+	o.addInt(key, v, true)
 }
 
 func (o *Obj2) Add(key string, v interface{}) {
-	var val interface{}
-
-	switch obj := v.(type) {
-	case *Float:
+	if obj, ok := v.(*Float); ok {
 		o.expect(key, TypeDecimal)
-		val = o.Get(key).(*Float).Add(obj)
+		o.Set(key, o.Get(key).(*Float).Add(obj))
+		return
+	}
+	o.addInt(key, v, false)
+}
+
+// addInt adds (or subtracts) the integer to an integer field. The
+// arithmetic is done with big integers and the result must be in
+// the range of the field (i.e. an uint256 cannot be negative).
+func (o *Obj2) addInt(key string, v interface{}, sub bool) {
+	field := o.getField(key)
+
+	min, max, ok := intBounds(field.Type)
+	if !ok {
+		o.objErr.finish(&ErrorEvent{
+			Type: ErrorEventFieldBadType,
+			Err:  fmt.Errorf("field %s of type %s is not an integer", key, field.Type),
+		})
+	}
+	delta, ok := toBigInt(v)
+	if !ok {
+		o.objErr.finish(&ErrorEvent{
+			Type: ErrorEventFieldBadType,
+			Err:  fmt.Errorf("field %s cannot add %T", key, v),
+		})
+	}
 
-	case uint64:
-		o.expect(key, TypeUint)
-		val = o.Get(key).(uint64) + obj
+	// an empty field is zero
+	current := new(big.Int)
+	if val, ok := o.GetOk(key); ok {
+		current, _ = toBigInt(val)
+	}
 
+	res := new(big.Int)
+	if sub {
+		res.Sub(current, delta)
+	} else {
+		res.Add(current, delta)
+	}
+	if res.Cmp(min) < 0 {
+		o.objErr.finish(&ErrorEvent{
+			Type: ErrorEventUnderflow,
+			Err:  fmt.Errorf("field %s underflow: %s is lower than %s", key, res, min),
+		})
+	}
+	if res.Cmp(max) > 0 {
+		o.objErr.finish(&ErrorEvent{
+			Type: ErrorEventOverflow,
+			Err:  fmt.Errorf("field %s overflow: %s is higher than %s", key, res, max),
+		})
+	}
+
+	switch field.Type {
+	case TypeInt:
+		o.Set(key, res.Int64())
 	default:
-		panic("Not expected")
+		o.Set(key, res)
 	}
-	o.Set(key, val)
 }
 
 /*
@@ -182,14 +270,34 @@ func (o *Obj2) Copy() *Obj2 {
 	for k, v := range o.vals {
 		oo.vals[k] = v
 	}
+	oo.sets = nil
 	return oo
 }
 
 func (s *Snapshot) save() []*protosdk.Diff {
 	diffs := []*protosdk.Diff{}
 
-	for _, obj := range s.trackedObjs {
-		if obj.isChanged() {
+	// the diffs follow the order in which the objects were accessed
+	// so that the referenced objects are usually created first
+	for _, obj := range s.trackedOrder {
+		obj.sets = nil
+
+		if obj.removed {
+			// the object is not cached anymore so that the next
+			// blocks do not read it. If it was created in this
+			// block there is nothing to delete in the state.
+			s.inmemStore.remove(string(obj.id))
+			if !obj.created {
+				diffs = append(diffs, &protosdk.Diff{
+					Table:    obj.table,
+					Keys:     obj.key,
+					Deletion: true,
+				})
+			}
+			continue
+		}
+
+		if obj.isChanged() || obj.created {
 			obj2 := obj.Copy()
 
 			diff := &protosdk.Diff{
@@ -210,6 +318,7 @@ func (s *Snapshot) save() []*protosdk.Diff {
 
 			s.inmemStore.add(string(obj2.id), obj2)
 			obj.changes = map[string]string{} // reset changes in parent
+			obj.created = false
 		}
 	}
 	return diffs
@@ -233,20 +342,35 @@ func (s *Snapshot) create(table string, schema *Table, id string, keys map[strin
 		vals:    map[string]string{},
 		changes: map[string]string{},
 	}
-	s.trackedObjs[id] = obj
+	s.track(id, obj)
 	return obj
 }
 
+// track adds the object to the objects modified in the block
+func (s *Snapshot) track(id string, obj *Obj2) {
+	s.trackedObjs[id] = obj
+	s.trackedOrder = append(s.trackedOrder, obj)
+}
+
+// isRemoved returns true if the object was removed in the block
+func (s *Snapshot) isRemoved(id string) bool {
+	obj, ok := s.trackedObjs[id]
+	return ok && obj.removed
+}
+
 func (s *Snapshot) getOk(table string, id string) (*Obj2, bool) {
 	// check tracked objects
 	if obj, ok := s.trackedObjs[id]; ok {
+		if obj.removed {
+			return nil, false
+		}
 		return obj, true
 	}
 
 	// check inmemory cache
 	obj, ok := s.inmemStore.get(id)
 	if ok {
-		s.trackedObjs[id] = obj
+		s.track(id, obj)
 		return obj, true
 	}
 	return nil, false
@@ -260,10 +384,20 @@ type Snapshot struct {
 	schemas     map[string]*Table
 	inmemStore  *inmemStore
 	trackedObjs map[string]*Obj2
+	// trackedOrder is the order in which the objects were tracked. It includes
+	// the objects removed in the block even if they were created again.
+	trackedOrder []*Obj2
+	sources      []*DataSource
+	txns         map[web3.Hash]*web3.Transaction
+	receipts     map[web3.Hash]*web3.Receipt
+
+	// handler is the handler running (if any)
+	handler *handlerCtx
 }
 
 func (s *Snapshot) reset() {
 	s.trackedObjs = map[string]*Obj2{}
+	s.trackedOrder = nil
 }
 
 func newSnapshot() *Snapshot {
@@ -327,6 +461,35 @@ type ErrorEvent struct {
 	Type        string
 	Description string
 	Err         error
+
+	// Block is the number of the block being processed
+	Block uint64
+
+	// TxHash and LogIndex are the transaction and the index of the log
+	// of the event that failed. For calls, the log index is not set.
+	TxHash   web3.Hash
+	LogIndex uint64
+
+	// Address is the contract that emitted the event or received the call
+	Address web3.Address
+
+	// Tracker is the name of the tracker that failed
+	Tracker string
+
+	handler *handlerCtx
+}
+
+func (e *ErrorEvent) Error() string {
+	msg := e.Type
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if e.Tracker != "" {
+		msg += fmt.Sprintf(" (tracker %s, block %d, tx %s, log %d)", e.Tracker, e.Block, e.TxHash, e.LogIndex)
+	} else {
+		msg += fmt.Sprintf(" (block %d)", e.Block)
+	}
+	return msg
 }
 
 const (
@@ -338,10 +501,18 @@ const (
 	ErrorEventFieldBadType      = "ErrorFieldBadType"
 	ErrorEventSchemaNotFound    = "ErrorSchemaNotFound"
 	ErrorEventIncorrectIdFields = "ErrorIncorrectIdFields"
+	ErrorEventTemplateNotFound  = "ErrorTemplateNotFound"
+	ErrorEventPanic             = "ErrorPanic"
+	ErrorEventTransaction       = "ErrorTransaction"
+	ErrorEventContractCall      = "ErrorContractCall"
 	ErrorEventGeneric           = "ErrorEventGeneric"
+	ErrorEventOverflow          = "ErrorOverflow"
+	ErrorEventUnderflow         = "ErrorUnderflow"
+	ErrorEventObjectRemoved     = "ErrorObjectRemoved"
 )
 
 func (s *Snapshot) finish(evnt *ErrorEvent) {
+	s.locate(evnt)
 	s.err = evnt
 	runtime.Goexit()
 }
@@ -355,8 +526,9 @@ func (s *Snapshot) Get(tableName string, keyRaw ...interface{}) *Obj2 {
 	}
 
 	var dataObj *Obj
-	if s.provider.resolver != nil {
-		// not found, try to search it still on the resolver (if any)
+	if s.provider.resolver != nil && !s.isRemoved(idStr) {
+		// not found, try to search it still on the resolver (if any). An
+		// object removed in this block is created again instead.
 		raw, err := s.provider.resolver.GetObj2(tableName, keysMap)
 		if err != nil {
 			s.finish(&ErrorEvent{
@@ -382,26 +554,39 @@ func (s *Snapshot) Get(tableName string, keyRaw ...interface{}) *Obj2 {
 			vals:    map[string]string{},
 			changes: map[string]string{},
 		}
-		// move all the non key values
-		for k, v := range dataObj.Data {
-			if _, ok := keysMap[k]; !ok {
-				obj.vals[k] = v
+		// move all the non key values. The names of the columns
+		// are lowercase since Postgresql folds the identifiers.
+		for _, field := range table.Fields {
+			if _, ok := keysMap[field.Name]; ok {
+				continue
+			}
+			v, ok := dataObj.Data[field.Name]
+			if !ok {
+				v, ok = dataObj.Data[strings.ToLower(field.Name)]
+			}
+			if ok {
+				obj.vals[field.Name] = v
 			}
 		}
 
 		// add the object to cache
 		s.inmemStore.add(idStr, obj.Copy())
+		s.track(idStr, obj)
 	}
 
 	// pass a reference so that the object can call errors
 	obj.objErr = s
 
-	// initialize the default values
-	for _, field := range table.Fields {
-		if field.Default != nil {
-			// set will already handle the string conversion
-			obj.Set(field.Name, field.Default)
+	if obj.created {
+		// initialize the default values
+		for _, field := range table.Fields {
+			if field.Default != nil {
+				// set will already handle the string conversion
+				obj.Set(field.Name, field.Default)
+			}
 		}
+		// the defaults are not values set by the handlers
+		obj.sets = nil
 	}
 
 	idFields := table.getIDS()
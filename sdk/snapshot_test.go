@@ -1,5 +1,229 @@
 package sdk
 
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	protosdk "github.com/umbracle/eth-indexer/sdk/proto"
+	"github.com/umbracle/go-web3"
+)
+
+func TestSnapshot_Ref(t *testing.T) {
+	var handler func(req *HandlerReq)
+
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"pair": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "address", Type: TypeAddress, ID: true},
+						{Name: "name", Type: TypeString, Default: "pair"},
+					},
+				},
+			},
+			"swap": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "id", Type: TypeString, ID: true},
+						{Name: "pair", Type: TypeAddress, References: &Reference{Table: "pair"}},
+					},
+				},
+			},
+		},
+		BlockTrackers: []*BlockTracker{
+			{
+				Interval: 1,
+				Handler: func(req *HandlerReq) {
+					handler(req)
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	// the field of the reference is resolved to the id of the table
+	assert.Equal(t, "address", p.Resources["swap"].Schema.getField("pair").References.Field)
+
+	var ref, empty *Obj2
+	handler = func(req *HandlerReq) {
+		pair := req.Get("pair", "0x1")
+		swap := req.Get("swap", "a")
+		swap.Set("pair", "0x1")
+
+		ref = swap.Ref("pair")
+		empty = req.Get("swap", "b").Ref("pair")
+
+		// the referenced object keeps the changes of the handler
+		ref.Set("name", "b")
+		assert.Equal(t, pair, ref)
+		assert.Equal(t, "b", pair.Get("name"))
+	}
+	diffs, evntErr := p.Process(&Action{BlockNum: 2, BlockTick: true})
+	assert.Nil(t, evntErr)
+	assert.Nil(t, empty)
+	assert.NotNil(t, ref)
+
+	// the diffs follow the order of access and include the
+	// objects created without any change
+	tables := []string{}
+	for _, diff := range diffs {
+		assert.True(t, diff.Creation)
+		tables = append(tables, diff.Table)
+	}
+	assert.Equal(t, []string{"pair", "swap", "swap"}, tables)
+
+	// not a reference
+	handler = func(req *HandlerReq) {
+		req.Get("pair", "0x1").Ref("name")
+	}
+	_, evntErr = p.Process(&Action{BlockNum: 3, BlockTick: true})
+	assert.NotNil(t, evntErr)
+	assert.Equal(t, ErrorEventFieldBadType, evntErr.Type)
+}
+
+func TestSnapshot_Remove(t *testing.T) {
+	var handler func(req *HandlerReq)
+
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"pair": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "address", Type: TypeAddress, ID: true},
+						{Name: "name", Type: TypeString},
+					},
+				},
+			},
+		},
+		Snapshots: map[string]*Snapshot2{
+			"pair_window": {
+				Table: "pair",
+				Index: []string{"name"},
+			},
+		},
+		BlockTrackers: []*BlockTracker{
+			{
+				Interval: 1,
+				Handler: func(req *HandlerReq) {
+					handler(req)
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	process := func(num uint64) (pairs, windows []*protosdk.Diff) {
+		diffs, evntErr := p.Process(&Action{BlockNum: num, BlockTick: true})
+		assert.Nil(t, evntErr)
+		for _, diff := range diffs {
+			if diff.Table == "pair" {
+				pairs = append(pairs, diff)
+			} else {
+				windows = append(windows, diff)
+			}
+		}
+		return
+	}
+
+	handler = func(req *HandlerReq) {
+		req.Get("pair", "0x1").Set("name", "a")
+	}
+	pairs, _ := process(2)
+	assert.Len(t, pairs, 1)
+	assert.True(t, pairs[0].Creation)
+
+	// the object is deleted and evicted from the cache
+	var id string
+	handler = func(req *HandlerReq) {
+		pair := req.Get("pair", "0x1")
+		id = string(pair.id)
+		pair.Remove()
+
+		_, ok := req.GetOk("pair", "0x1")
+		assert.False(t, ok)
+	}
+	pairs, windows := process(3)
+	assert.Len(t, pairs, 1)
+	assert.True(t, pairs[0].Deletion)
+	assert.Empty(t, pairs[0].Vals)
+	assert.NotEmpty(t, pairs[0].Keys)
+
+	_, ok := p.snap.inmemStore.get(id)
+	assert.False(t, ok)
+
+	// the window records the tombstone
+	assert.Len(t, windows, 1)
+	assert.Equal(t, "true", windows[0].Vals["removed"])
+
+	// the object is created again
+	handler = func(req *HandlerReq) {
+		pair := req.Get("pair", "0x1")
+		assert.True(t, pair.IsNew())
+		pair.Set("name", "a")
+	}
+	pairs, _ = process(4)
+	assert.Len(t, pairs, 1)
+	assert.True(t, pairs[0].Creation)
+
+	// the object is removed and created again in the same block
+	handler = func(req *HandlerReq) {
+		req.Get("pair", "0x1").Remove()
+
+		pair := req.Get("pair", "0x1")
+		assert.True(t, pair.IsNew())
+		assert.False(t, pair.IsRemoved())
+		pair.Set("name", "b")
+	}
+	pairs, windows = process(5)
+	assert.Len(t, pairs, 2)
+	assert.True(t, pairs[0].Deletion)
+	assert.True(t, pairs[1].Creation)
+	assert.Equal(t, "b", pairs[1].Vals["name"])
+
+	assert.Len(t, windows, 1)
+	assert.Equal(t, "false", windows[0].Vals["removed"])
+	assert.Equal(t, "b", windows[0].Vals["name"])
+
+	// an object created and removed in the block is not in the state
+	handler = func(req *HandlerReq) {
+		pair := req.Get("pair", "0x2")
+		pair.Set("name", "c")
+		pair.Remove()
+	}
+	pairs, windows = process(6)
+	assert.Empty(t, pairs)
+	assert.Empty(t, windows)
+
+	// the removed object cannot be modified
+	handler = func(req *HandlerReq) {
+		pair := req.Get("pair", "0x1")
+		pair.Remove()
+		pair.Set("name", "d")
+	}
+	_, evntErr := p.Process(&Action{BlockNum: 7, BlockTick: true})
+	assert.NotNil(t, evntErr)
+	assert.Equal(t, ErrorEventObjectRemoved, evntErr.Type)
+}
+
+func TestHandlerReq_TrackTemplateNotFound(t *testing.T) {
+	p := &Provider{
+		BlockTrackers: []*BlockTracker{
+			{
+				Interval: 1,
+				Handler: func(req *HandlerReq) {
+					req.Track("pair%d", web3.Address{0x1})
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	_, evntErr := p.Process(&Action{BlockNum: 1, BlockTick: true})
+	assert.NotNil(t, evntErr)
+	assert.Equal(t, ErrorEventTemplateNotFound, evntErr.Type)
+	assert.Equal(t, "template 'pair%d' is not defined in the provider", evntErr.Err.Error())
+}
+
 /*
 func TestSnapshot(t *testing.T) {
 	s1 := &Snapshot1{tree: iradix.New()}
@@ -0,0 +1,91 @@
+package sdk
+
+import (
+	"fmt"
+	"time"
+)
+
+// SplitFunc returns the start of the window of a snapshot that includes the block
+type SplitFunc func(block *Block) interface{}
+
+// Split divides the blocks in the windows of a snapshot
+type Split struct {
+	// Column is the name of the column with the start of the window
+	Column string
+
+	// Type is the type of the column, TypeUint for a block
+	// number or TypeTimestamp for the time of the block
+	Type FieldType
+
+	Func SplitFunc
+
+	// Convert converts the column of the snapshot tables created
+	// by a previous version with the windows in text (optional)
+	Convert *Conversion
+}
+
+// getSplit returns the split of the snapshot, one window per block by default
+func (s *Snapshot2) getSplit() *Split {
+	if s.Split != nil {
+		return s.Split
+	}
+	return BlockSplit(1)
+}
+
+// BlockSplit splits the blocks in windows of 'size' blocks. The
+// window starts at the first block (i.e. 100, 200...).
+func BlockSplit(size uint64) *Split {
+	return &Split{
+		Column: "block",
+		Type:   TypeUint,
+		Func: func(block *Block) interface{} {
+			return block.Number / size * size
+		},
+		// the previous versions stored the index of the window
+		Convert: &Conversion{
+			From:  "text",
+			Using: fmt.Sprintf("block::numeric * %d", size),
+		},
+	}
+}
+
+// TimeSplit splits the blocks in windows of the duration aligned with
+// the unix time (UTC) by the timestamp of the blocks
+func TimeSplit(d time.Duration) *Split {
+	if d < time.Second {
+		// the timestamps of the blocks are in seconds
+		d = time.Second
+	}
+	return &Split{
+		Column: "timestamp",
+		Type:   TypeTimestamp,
+		Func: func(block *Block) interface{} {
+			size := uint64(d / time.Second)
+			return time.Unix(int64(block.Timestamp/size*size), 0).UTC()
+		},
+	}
+}
+
+// HourSplit splits the blocks in hours (UTC)
+func HourSplit() *Split {
+	return TimeSplit(time.Hour)
+}
+
+// DaySplit splits the blocks in days (UTC)
+func DaySplit() *Split {
+	return TimeSplit(24 * time.Hour)
+}
+
+// WeekSplit splits the blocks in weeks starting on Monday (UTC)
+func WeekSplit() *Split {
+	return &Split{
+		Column: "timestamp",
+		Type:   TypeTimestamp,
+		Func: func(block *Block) interface{} {
+			day := DaySplit().Func(block).(time.Time)
+			// the days since the last monday
+			days := (int(day.Weekday()) + 6) % 7
+			return day.AddDate(0, 0, -days)
+		},
+	}
+}
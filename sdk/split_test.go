@@ -0,0 +1,89 @@
+package sdk
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit_Buckets(t *testing.T) {
+	// Wednesday 2021-03-10 15:42:10 UTC
+	block := &Block{Number: 1234, Timestamp: uint64(time.Date(2021, 3, 10, 15, 42, 10, 0, time.UTC).Unix())}
+
+	assert.Equal(t, uint64(1200), BlockSplit(100).Func(block))
+	assert.Equal(t, time.Date(2021, 3, 10, 15, 0, 0, 0, time.UTC), HourSplit().Func(block))
+	assert.Equal(t, time.Date(2021, 3, 10, 15, 40, 0, 0, time.UTC), TimeSplit(5*time.Minute).Func(block))
+	assert.Equal(t, time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC), DaySplit().Func(block))
+	assert.Equal(t, time.Date(2021, 3, 8, 0, 0, 0, 0, time.UTC), WeekSplit().Func(block))
+
+	// a monday is the start of the week
+	monday := &Block{Timestamp: uint64(time.Date(2021, 3, 8, 0, 0, 0, 0, time.UTC).Unix())}
+	assert.Equal(t, time.Date(2021, 3, 8, 0, 0, 0, 0, time.UTC), WeekSplit().Func(monday))
+}
+
+func TestSplit_Snapshot(t *testing.T) {
+	var handler func(req *HandlerReq)
+
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"pair": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "address", Type: TypeAddress, ID: true},
+						{Name: "reserve", Type: TypeUint},
+					},
+				},
+			},
+		},
+		Snapshots: map[string]*Snapshot2{
+			"pair_daily": {
+				Table:      "pair",
+				Aggregates: []*Aggregate{{Field: "reserve", Kind: AggregateCount}},
+				Split:      DaySplit(),
+			},
+		},
+		BlockTrackers: []*BlockTracker{
+			{
+				Interval: 1,
+				Handler: func(req *HandlerReq) {
+					handler(req)
+				},
+			},
+		},
+	}
+	assert.NoError(t, p.Init())
+
+	// the start of the window is a timestamp column
+	field := p.schemas["pair_daily"].getField("timestamp")
+	assert.Equal(t, TypeTimestamp, field.Type)
+	assert.True(t, field.ID)
+
+	day := time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC)
+	process := func(num uint64, ts time.Time, fn func(req *HandlerReq)) *ErrorEvent {
+		handler = fn
+		act := &Action{BlockNum: num, BlockTick: true, Block: &Block{Number: num, Timestamp: uint64(ts.Unix())}}
+		_, evntErr := p.Process(act)
+		return evntErr
+	}
+	incr := func(req *HandlerReq) {
+		req.Get("pair", "0x1").Add("reserve", uint64(1))
+	}
+
+	assert.Nil(t, process(1, day.Add(time.Hour), incr))
+	assert.Nil(t, process(2, day.Add(23*time.Hour), incr))
+	assert.Nil(t, process(3, day.Add(25*time.Hour), incr))
+
+	var today, tomorrow *Obj2
+	assert.Nil(t, process(4, day.Add(26*time.Hour), func(req *HandlerReq) {
+		today = req.Get("pair_daily", "0x1", day)
+		tomorrow = req.Get("pair_daily", "0x1", day.AddDate(0, 0, 1))
+	}))
+	assert.Equal(t, "2", today.Get("reserve_count").(fmt.Stringer).String())
+	assert.Equal(t, "1", tomorrow.Get("reserve_count").(fmt.Stringer).String())
+
+	// the timestamp of the block is required
+	evntErr := process(5, time.Unix(0, 0), incr)
+	assert.NotNil(t, evntErr)
+}
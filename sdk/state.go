@@ -1,5 +1,7 @@
 package sdk
 
+import "github.com/umbracle/go-web3"
+
 // Obj is an entity in the state
 type Obj struct {
 	Data map[string]string
@@ -11,6 +13,18 @@ type StateResolver interface {
 	GetObjs2(q *Query) ([]*Obj, error)
 }
 
+// TransactionResolver is an optional interface of the state resolver
+// to resolve the transactions stored by the indexer
+type TransactionResolver interface {
+	GetTransaction(hash web3.Hash) (*web3.Transaction, error)
+}
+
+// Client is the json-rpc client used to query the chain. It is
+// implemented by both the go-web3 client and the endpoint pool.
+type Client interface {
+	Call(method string, out interface{}, params ...interface{}) error
+}
+
 const (
 	AscOrder  = "asc"
 	DescOrder = "desc"
@@ -0,0 +1,107 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/umbracle/go-web3"
+)
+
+// Transaction returns the transaction that emitted the event (or the call).
+// The transaction is queried the first time it is accessed.
+func (h *HandlerReq) Transaction() *web3.Transaction {
+	return h.getTransaction(h.txHash())
+}
+
+// Receipt returns the receipt of the transaction that emitted the event (or
+// the call). The receipt is queried the first time it is accessed.
+func (h *HandlerReq) Receipt() *web3.Receipt {
+	return h.getReceipt(h.txHash())
+}
+
+func (h *HandlerReq) txHash() web3.Hash {
+	if h.Call != nil {
+		return h.Call.TxHash
+	}
+	var hash web3.Hash
+	if err := hash.UnmarshalText([]byte(h.Evnt.TxHash)); err != nil {
+		h.finish(&ErrorEvent{
+			Type: ErrorEventTransaction,
+			Err:  err,
+		})
+	}
+	return hash
+}
+
+func (s *Snapshot) getTransaction(hash web3.Hash) *web3.Transaction {
+	if txn, ok := s.txns[hash]; ok {
+		return txn
+	}
+	if resolver, ok := s.provider.resolver.(TransactionResolver); ok {
+		// the transaction might be stored already
+		txn, err := resolver.GetTransaction(hash)
+		if err != nil {
+			s.finish(&ErrorEvent{
+				Type: ErrorEventTransaction,
+				Err:  err,
+			})
+		}
+		if txn != nil {
+			s.txns[hash] = txn
+			return txn
+		}
+	}
+	if s.provider.client == nil {
+		s.finish(&ErrorEvent{
+			Type: ErrorEventTransaction,
+			Err:  fmt.Errorf("client not set"),
+		})
+	}
+	var txn *web3.Transaction
+	err := s.provider.client.Call("eth_getTransactionByHash", &txn, hash)
+	if err == nil && txn == nil {
+		err = fmt.Errorf("transaction %s not found", hash)
+	}
+	if err != nil {
+		s.finish(&ErrorEvent{
+			Type: ErrorEventTransaction,
+			Err:  err,
+		})
+	}
+	s.txns[hash] = txn
+	return txn
+}
+
+func (s *Snapshot) getReceipt(hash web3.Hash) *web3.Receipt {
+	if receipt, ok := s.receipts[hash]; ok {
+		return receipt
+	}
+	if s.provider.client == nil {
+		s.finish(&ErrorEvent{
+			Type: ErrorEventTransaction,
+			Err:  fmt.Errorf("client not set"),
+		})
+	}
+	var receipt *web3.Receipt
+	err := s.provider.client.Call("eth_getTransactionReceipt", &receipt, hash)
+	if err == nil && receipt == nil {
+		err = fmt.Errorf("receipt %s not found", hash)
+	}
+	if err != nil {
+		s.finish(&ErrorEvent{
+			Type: ErrorEventTransaction,
+			Err:  err,
+		})
+	}
+	s.receipts[hash] = receipt
+	return receipt
+}
+
+// Transactions returns the transactions queried by the handlers
+// during the last call to Process.
+func (p *Provider) Transactions() []*web3.Transaction {
+	res := []*web3.Transaction{}
+	for _, txn := range p.snap.txns {
+		res = append(res, txn)
+	}
+	return res
+}
@@ -0,0 +1,361 @@
+package sdk
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ValidationError is the list of problems found in the definition of a provider
+type ValidationError struct {
+	Problems []string
+}
+
+func (v *ValidationError) Error() string {
+	return fmt.Sprintf("%d problems found in the provider:\n- %s", len(v.Problems), strings.Join(v.Problems, "\n- "))
+}
+
+// identifierRe matches the identifiers that Postgresql accepts without quotes
+var identifierRe = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+// maxIdentifierLen is the maximum length of an identifier in Postgresql
+const maxIdentifierLen = 63
+
+// reservedWords are the keywords of Postgresql that cannot be used as
+// the name of a table or a column
+var reservedWords = map[string]struct{}{}
+
+func init() {
+	for _, word := range strings.Fields(`all analyse analyze and any array as asc asymmetric both case cast check
+		collate column constraint create current_catalog current_date current_role current_time
+		current_timestamp current_user default deferrable desc distinct do else end except false
+		fetch for foreign from grant group having in initially intersect into lateral leading limit
+		localtime localtimestamp not null offset on only or order placing primary references
+		returning select session_user some symmetric table then to trailing true union unique user
+		using variadic when where window with`) {
+		reservedWords[word] = struct{}{}
+	}
+}
+
+func validateIdentifier(kind, name string) string {
+	if name == "" {
+		return fmt.Sprintf("%s without name", kind)
+	}
+	if !identifierRe.MatchString(name) {
+		return fmt.Sprintf("%s '%s' is not a valid identifier, use only letters, digits and underscores", kind, name)
+	}
+	if len(name) > maxIdentifierLen {
+		return fmt.Sprintf("%s '%s' is longer than %d characters", kind, name, maxIdentifierLen)
+	}
+	if _, ok := reservedWords[strings.ToLower(name)]; ok {
+		return fmt.Sprintf("%s '%s' is a reserved word", kind, name)
+	}
+	return ""
+}
+
+func validFieldType(typ FieldType) bool {
+	return typ >= TypeAddress && typ <= TypeBigInt
+}
+
+// validator collects the problems of the provider
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) identifier(kind, name string) {
+	if problem := validateIdentifier(kind, name); problem != "" {
+		v.problems = append(v.problems, problem)
+	}
+}
+
+// Validate checks the definition of the provider and returns
+// a ValidationError with all the problems found (if any)
+func (p *Provider) Validate() error {
+	v := &validator{}
+
+	if p.Filter != nil {
+		if err := validateSources(p.Filter.GetSources()); err != nil {
+			v.addf("filter: %v", err)
+		}
+	}
+
+	tables := map[string]*Table{}
+	for _, name := range sortedKeys(p.Resources) {
+		res := p.Resources[name]
+		if res == nil || res.Schema == nil {
+			v.addf("table '%s' without schema", name)
+			continue
+		}
+		tables[name] = res.Schema
+	}
+	for _, name := range sortedKeys(tables) {
+		v.validateTable(name, tables[name], tables)
+	}
+
+	for _, name := range sortedKeys(p.Snapshots) {
+		v.validateSnapshot(name, p.Snapshots[name], tables)
+	}
+	for _, name := range sortedKeys(p.Candles) {
+		v.validateCandle(name, p.Candles[name], tables, p.Snapshots)
+	}
+
+	for indx, t := range p.Trackers {
+		v.validateTracker(fmt.Sprintf("tracker %d", indx), t)
+	}
+	for _, name := range sortedKeys(p.Templates) {
+		for indx, t := range p.Templates[name].Trackers {
+			v.validateTracker(fmt.Sprintf("tracker %d of template '%s'", indx, name), t)
+		}
+	}
+	for indx, t := range p.CallTrackers {
+		if t.Method == nil {
+			v.addf("call tracker %d without method", indx)
+		}
+		if t.Handler == nil {
+			v.addf("call tracker %d without handler", indx)
+		}
+	}
+	for indx, t := range p.BlockTrackers {
+		if t.Interval == 0 {
+			v.addf("block tracker %d without interval", indx)
+		}
+		if t.Handler == nil {
+			v.addf("block tracker %d without handler", indx)
+		}
+	}
+
+	if len(v.problems) != 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+func (v *validator) validateTable(name string, table *Table, tables map[string]*Table) {
+	v.identifier("table", name)
+
+	// the identifiers are case insensitive in Postgresql
+	names := map[string]struct{}{}
+	ids := 0
+	for _, field := range table.Fields {
+		if problem := validateIdentifier("field", field.Name); problem != "" {
+			v.addf("table '%s': %s", name, problem)
+			continue
+		}
+		if _, ok := names[strings.ToLower(field.Name)]; ok {
+			v.addf("table '%s': field '%s' is duplicated", name, field.Name)
+		}
+		names[strings.ToLower(field.Name)] = struct{}{}
+
+		if !validFieldType(field.Type) {
+			v.addf("table '%s': field '%s' has an unknown type %d", name, field.Name, int(field.Type))
+			continue
+		}
+		if field.ID {
+			ids++
+		}
+		if field.Default != nil {
+			if _, err := field.Encode(field.Default); err != nil {
+				v.addf("table '%s': the default of field '%s' (%T) does not match the type %s", name, field.Name, field.Default, field.Type)
+			}
+		}
+		if field.References != nil {
+			v.validateReference(name, field, tables)
+			if reverse := field.References.Reverse; reverse != "" {
+				v.identifier("reverse field", reverse)
+			}
+		}
+	}
+	if ids == 0 {
+		v.addf("table '%s' does not have any ID field", name)
+	}
+
+	indexes := map[string]struct{}{}
+	for indx, index := range table.Indexes {
+		if index == nil || len(index.Fields) == 0 {
+			v.addf("table '%s': index %d does not have any field", name, indx)
+			continue
+		}
+		iname := index.Name
+		if iname == "" {
+			iname = indexName(name, index)
+		} else if problem := validateIdentifier("index", index.Name); problem != "" {
+			v.addf("table '%s': %s", name, problem)
+		}
+		if _, ok := indexes[strings.ToLower(iname)]; ok {
+			v.addf("table '%s': index '%s' is duplicated", name, iname)
+		}
+		indexes[strings.ToLower(iname)] = struct{}{}
+
+		for _, field := range index.Fields {
+			if table.getField(field.Name) == nil {
+				v.addf("table '%s': field '%s' of index '%s' does not exist", name, field.Name, iname)
+			}
+		}
+	}
+}
+
+func (v *validator) validateReference(name string, field *Field, tables map[string]*Table) {
+	ref := field.References
+	target, ok := tables[ref.Table]
+	if !ok {
+		v.addf("table '%s': field '%s' references the table '%s' which does not exist", name, field.Name, ref.Table)
+		return
+	}
+
+	// the foreign key references the id of the table
+	ids := target.getIDS()
+	if len(ids) != 1 {
+		v.addf("table '%s': field '%s' references the table '%s' which does not have a single ID field", name, field.Name, ref.Table)
+		return
+	}
+	targetField := ids[0]
+	if ref.Field != "" && ref.Field != targetField.Name {
+		v.addf("table '%s': field '%s' references the field '%s.%s' which is not the ID of the table", name, field.Name, ref.Table, ref.Field)
+		return
+	}
+	if targetField.Type != field.Type {
+		v.addf("table '%s': field '%s' (%s) references the field '%s.%s' of a different type (%s)", name, field.Name, field.Type, ref.Table, targetField.Name, targetField.Type)
+	}
+}
+
+func (v *validator) validateSnapshot(name string, snapshot *Snapshot2, tables map[string]*Table) {
+	v.identifier("snapshot", name)
+
+	if _, ok := tables[name]; ok {
+		v.addf("snapshot '%s' has the same name as a table", name)
+	}
+	table, ok := tables[snapshot.Table]
+	if !ok {
+		v.addf("snapshot '%s': table '%s' does not exist", name, snapshot.Table)
+		return
+	}
+	if len(snapshot.Index) == 0 && len(snapshot.Aggregates) == 0 {
+		v.addf("snapshot '%s' does not have any index field or aggregate", name)
+	}
+
+	split := snapshot.getSplit()
+	v.validateSplit("snapshot '"+name+"'", split)
+
+	// the snapshot table includes the ids, the index fields, the
+	// aggregates, the tombstone and the start of the window
+	columns := map[string]struct{}{
+		strings.ToLower(split.Column): {},
+		snapshotRemovedColumn:         {},
+	}
+	column := func(col string) {
+		if _, ok := columns[strings.ToLower(col)]; ok {
+			v.addf("snapshot '%s': column '%s' is duplicated", name, col)
+		}
+		columns[strings.ToLower(col)] = struct{}{}
+	}
+	for _, field := range table.getIDS() {
+		column(field.Name)
+	}
+	for _, fieldName := range snapshot.Index {
+		if table.getField(fieldName) == nil {
+			v.addf("snapshot '%s': index field '%s' does not exist in table '%s'", name, fieldName, snapshot.Table)
+			continue
+		}
+		column(fieldName)
+	}
+	for _, a := range snapshot.Aggregates {
+		field := table.getField(a.Field)
+		if field == nil {
+			v.addf("snapshot '%s': aggregate field '%s' does not exist in table '%s'", name, a.Field, snapshot.Table)
+			continue
+		}
+		if _, ok := aggregateType(a.Kind, field.Type); !ok {
+			v.addf("snapshot '%s': field '%s' of type %s cannot be aggregated with %s", name, a.Field, field.Type, a.Kind)
+			continue
+		}
+		if problem := validateIdentifier("aggregate", a.column()); problem != "" {
+			v.addf("snapshot '%s': %s", name, problem)
+			continue
+		}
+		column(a.column())
+		if a.Kind == AggregateAvg {
+			column(a.samplesColumn())
+		}
+	}
+}
+
+func (v *validator) validateSplit(name string, split *Split) {
+	if split.Func == nil {
+		v.addf("%s: split without function", name)
+	}
+	if split.Type != TypeUint && split.Type != TypeTimestamp {
+		v.addf("%s: the split column must be an uint or a timestamp but it is %s", name, split.Type)
+	}
+	if problem := validateIdentifier("split column", split.Column); problem != "" {
+		v.addf("%s: %s", name, problem)
+	}
+}
+
+func (v *validator) validateCandle(name string, candle *Candle, tables map[string]*Table, snapshots map[string]*Snapshot2) {
+	v.identifier("candle", name)
+
+	if _, ok := tables[name]; ok {
+		v.addf("candle '%s' has the same name as a table", name)
+	}
+	if _, ok := snapshots[name]; ok {
+		v.addf("candle '%s' has the same name as a snapshot", name)
+	}
+	table, ok := tables[candle.Table]
+	if !ok {
+		v.addf("candle '%s': table '%s' does not exist", name, candle.Table)
+		return
+	}
+
+	if field := table.getField(candle.Field); field == nil {
+		v.addf("candle '%s': field '%s' does not exist in table '%s'", name, candle.Field, candle.Table)
+	} else if field.Type != TypeDecimal {
+		v.addf("candle '%s': field '%s' is %s but it must be a decimal", name, candle.Field, field.Type)
+	}
+	if candle.Volume != "" {
+		if field := table.getField(candle.Volume); field == nil {
+			v.addf("candle '%s': volume field '%s' does not exist in table '%s'", name, candle.Volume, candle.Table)
+		} else if !isNumeric(field.Type) {
+			v.addf("candle '%s': volume field '%s' is %s but it must be numeric", name, candle.Volume, field.Type)
+		}
+	}
+	if candle.Interval == nil {
+		v.addf("candle '%s' without interval", name)
+		return
+	}
+	v.validateSplit("candle '"+name+"'", candle.Interval)
+
+	// the ids of the table cannot collide with the columns of the candle
+	columns := []string{"open", "high", "low", "close", "volume", candle.Interval.Column}
+	for _, field := range table.getIDS() {
+		for _, col := range columns {
+			if strings.EqualFold(field.Name, col) {
+				v.addf("candle '%s': ID field '%s' of table '%s' collides with a column of the candle", name, field.Name, candle.Table)
+			}
+		}
+	}
+}
+
+func (v *validator) validateTracker(name string, t *Tracker) {
+	if t.Type == nil {
+		v.addf("%s without event", name)
+	}
+	if t.Handler == nil {
+		v.addf("%s without handler", name)
+	}
+}
+
+// sortedKeys returns the keys of a map with string keys sorted
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,167 @@
+package sdk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate_Problems(t *testing.T) {
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"token": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "address", Type: TypeAddress, ID: true},
+						{Name: "numPairs", Type: TypeUint, Default: 0},
+						{Name: "numpairs", Type: TypeUint},
+						{Name: "pair", Type: TypeAddress, References: &Reference{Table: "pair"}},
+					},
+				},
+			},
+			"order": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "amount", Type: TypeUint},
+						{Name: "token", Type: TypeUint, References: &Reference{Table: "token", Field: "address"}},
+						{Name: "bad-name", Type: TypeUint},
+					},
+				},
+			},
+		},
+		Snapshots: map[string]*Snapshot2{
+			"token_pairs": {
+				Table: "token",
+				Index: []string{"numPairs", "unknown"},
+			},
+		},
+		BlockTrackers: []*BlockTracker{
+			{},
+		},
+	}
+
+	err := p.Init()
+	assert.Error(t, err)
+
+	verr, ok := err.(*ValidationError)
+	assert.True(t, ok)
+
+	expected := []string{
+		"table 'order' is a reserved word",
+		"table 'order': field 'token' (uint) references the field 'token.address' of a different type (address)",
+		"table 'order': field 'bad-name' is not a valid identifier, use only letters, digits and underscores",
+		"table 'order' does not have any ID field",
+		"table 'token': the default of field 'numPairs' (int) does not match the type uint",
+		"table 'token': field 'numpairs' is duplicated",
+		"table 'token': field 'pair' references the table 'pair' which does not exist",
+		"snapshot 'token_pairs': index field 'unknown' does not exist in table 'token'",
+		"block tracker 0 without interval",
+		"block tracker 0 without handler",
+	}
+	assert.Equal(t, expected, verr.Problems)
+	assert.True(t, strings.HasPrefix(err.Error(), "10 problems found"))
+}
+
+func TestValidate_Valid(t *testing.T) {
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"pair": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "address", Type: TypeAddress, ID: true},
+						{Name: "name", Type: TypeString, Default: ""},
+					},
+				},
+			},
+			"token": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "address", Type: TypeAddress, ID: true},
+						{Name: "numPairs", Type: TypeUint, Default: uint64(0)},
+						{Name: "pair", Type: TypeAddress, References: &Reference{Table: "pair"}},
+					},
+				},
+			},
+		},
+		Snapshots: map[string]*Snapshot2{
+			"token_pairs": {
+				Table: "token",
+				Index: []string{"numPairs"},
+			},
+		},
+	}
+	assert.NoError(t, p.Validate())
+}
+
+func TestValidate_References(t *testing.T) {
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"pair": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "address", Type: TypeAddress, ID: true},
+						{Name: "name", Type: TypeString},
+					},
+				},
+			},
+			"swap": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "id", Type: TypeString, ID: true},
+						{Name: "pair", Type: TypeAddress, References: &Reference{Table: "pair", Field: "name"}},
+						{Name: "pair2", Type: TypeAddress, References: &Reference{Table: "pair", Reverse: "select"}},
+						{Name: "pair3", Type: TypeAddress, References: &Reference{Table: "swap"}},
+					},
+				},
+			},
+		},
+	}
+
+	verr, ok := p.Validate().(*ValidationError)
+	assert.True(t, ok)
+
+	expected := []string{
+		"table 'swap': field 'pair' references the field 'pair.name' which is not the ID of the table",
+		"reverse field 'select' is a reserved word",
+		"table 'swap': field 'pair3' (address) references the field 'swap.id' of a different type (string)",
+	}
+	assert.Equal(t, expected, verr.Problems)
+}
+
+func TestValidate_Indexes(t *testing.T) {
+	p := &Provider{
+		Resources: map[string]*Resource{
+			"swap": {
+				Schema: &Table{
+					Fields: []*Field{
+						{Name: "id", Type: TypeString, ID: true},
+						{Name: "timestamp", Type: TypeTimestamp},
+					},
+					Indexes: []*Index{
+						{Fields: []IndexField{Desc("timestamp")}},
+						{Name: "swap_timestamp_idx", Fields: []IndexField{Asc("timestamp")}},
+						{Name: "bad-name", Fields: []IndexField{Asc("unknown")}},
+						{},
+					},
+				},
+			},
+		},
+	}
+
+	verr, ok := p.Init().(*ValidationError)
+	assert.True(t, ok)
+
+	expected := []string{
+		"table 'swap': index 'swap_timestamp_idx' is duplicated",
+		"table 'swap': index 'bad-name' is not a valid identifier, use only letters, digits and underscores",
+		"table 'swap': field 'unknown' of index 'bad-name' does not exist",
+		"table 'swap': index 3 does not have any field",
+	}
+	assert.Equal(t, expected, verr.Problems)
+
+	// the default name of the index
+	p.Resources["swap"].Schema.Indexes = p.Resources["swap"].Schema.Indexes[:1]
+	assert.NoError(t, p.Init())
+	assert.Equal(t, "swap_timestamp_idx", p.Resources["swap"].Schema.Indexes[0].Name)
+}